@@ -0,0 +1,69 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore keeps payloads as files in a directory, the directory must be shared by publisher and consumer
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) Put(_ context.Context, key string, payload []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, payload, 0o640)
+}
+
+func (s *FileStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+
+	return payload, err
+}
+
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// path maps a key to a file inside the store directory, keys are read from message attributes and must not escape it
+func (s *FileStore) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return "", fmt.Errorf("blob: invalid key '%s'", key)
+	}
+
+	return filepath.Join(s.dir, key), nil
+}
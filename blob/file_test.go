@@ -0,0 +1,37 @@
+package blob
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore_PutGetDelete(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.Nil(t, err)
+
+	ctx := context.Background()
+	require.Nil(t, store.Put(ctx, "foo", []byte("bar baz")))
+
+	payload, err := store.Get(ctx, "foo")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("bar baz"), payload)
+
+	require.Nil(t, store.Delete(ctx, "foo"))
+	require.Nil(t, store.Delete(ctx, "foo"))
+
+	_, err = store.Get(ctx, "foo")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFileStore_InvalidKey(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.Nil(t, err)
+
+	for _, key := range []string{"", "..", "../foo", "foo/bar", `foo\bar`} {
+		_, err = store.Get(context.Background(), key)
+		assert.Error(t, err, key)
+	}
+}
@@ -0,0 +1,21 @@
+package blob
+
+import (
+	"context"
+	"errors"
+)
+
+// PointerAttribute is the message attribute carrying the key of an offloaded payload
+const PointerAttribute = "Payload-Pointer"
+
+// SizeAttribute is the message attribute carrying the size in bytes of an offloaded payload
+const SizeAttribute = "Payload-Size"
+
+var ErrNotFound = errors.New("blob: payload not found")
+
+// Store persists message payloads that exceed the SQS message size limit
+type Store interface {
+	Put(ctx context.Context, key string, payload []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,156 @@
+package codec
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes messages to SQS bodies, binary formats are base64 encoded as SQS only accepts text
+type Codec interface {
+	ContentType() string
+	Marshal(v interface{}) (string, error)
+	Unmarshal(body string, v interface{}) error
+}
+
+var (
+	JSON        Codec = jsonCodec{}
+	Protobuf    Codec = protobufCodec{}
+	MessagePack Codec = msgpackCodec{}
+	// Text passes string and []byte through unchanged
+	Text Codec = rawCodec{contentType: "text/plain"}
+	// Binary passes []byte through base64 encoded
+	Binary Codec = rawCodec{contentType: "application/octet-stream", base64: true}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (jsonCodec) Marshal(v interface{}) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+func (jsonCodec) Unmarshal(body string, v interface{}) error {
+	return json.Unmarshal([]byte(body), v)
+}
+
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (protobufCodec) Marshal(v interface{}) (string, error) {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return "", fmt.Errorf("codec: %T does not implement proto.Message", v)
+	}
+
+	raw, err := proto.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func (protobufCodec) Unmarshal(body string, v interface{}) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("codec: %T does not implement proto.Message", v)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return err
+	}
+
+	return proto.Unmarshal(raw, message)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+func (msgpackCodec) Marshal(v interface{}) (string, error) {
+	raw, err := msgpack.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func (msgpackCodec) Unmarshal(body string, v interface{}) error {
+	raw, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return err
+	}
+
+	return msgpack.Unmarshal(raw, v)
+}
+
+type rawCodec struct {
+	contentType string
+	base64      bool
+}
+
+func (c rawCodec) ContentType() string {
+	return c.contentType
+}
+
+func (c rawCodec) Marshal(v interface{}) (string, error) {
+	var raw []byte
+	switch value := v.(type) {
+	case string:
+		raw = []byte(value)
+	case []byte:
+		raw = value
+	case json.RawMessage:
+		raw = value
+	default:
+		return "", fmt.Errorf("codec: %s only passes string and []byte, got %T", c.contentType, v)
+	}
+
+	if c.base64 {
+		return base64.StdEncoding.EncodeToString(raw), nil
+	}
+
+	return string(raw), nil
+}
+
+func (c rawCodec) Unmarshal(body string, v interface{}) error {
+	raw := []byte(body)
+	if c.base64 {
+		var err error
+		if raw, err = base64.StdEncoding.DecodeString(body); err != nil {
+			return err
+		}
+	}
+
+	switch target := v.(type) {
+	case *string:
+		*target = string(raw)
+	case *[]byte:
+		*target = raw
+	case *json.RawMessage:
+		*target = raw
+	default:
+		return fmt.Errorf("codec: %s only decodes to *string and *[]byte, got %T", c.contentType, v)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type product struct {
+	SKU   string  `json:"sku" msgpack:"sku"`
+	Price float64 `json:"price" msgpack:"price"`
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	registry := DefaultRegistry()
+	expected := product{SKU: "foo", Price: 12.5}
+
+	for _, contentType := range []string{"application/json", "application/msgpack"} {
+		c, err := registry.Lookup(contentType)
+		require.Nil(t, err)
+
+		body, err := c.Marshal(expected)
+		require.Nil(t, err)
+
+		actual := product{}
+		require.Nil(t, registry.Unmarshal(contentType, body, &actual))
+		assert.Equal(t, expected, actual, contentType)
+	}
+}
+
+func TestProtobuf_RoundTrip(t *testing.T) {
+	body, err := Protobuf.Marshal(wrapperspb.String("foo"))
+	require.Nil(t, err)
+
+	actual := &wrapperspb.StringValue{}
+	require.Nil(t, Protobuf.Unmarshal(body, actual))
+	assert.True(t, proto.Equal(wrapperspb.String("foo"), actual))
+
+	_, err = Protobuf.Marshal(product{})
+	assert.Error(t, err)
+}
+
+func TestRaw_Passthrough(t *testing.T) {
+	body, err := Text.Marshal(`{"foo":"bar"}`)
+	require.Nil(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, body)
+
+	body, err = Binary.Marshal([]byte{0, 1, 2})
+	require.Nil(t, err)
+	assert.Equal(t, "AAEC", body)
+
+	raw := []byte{}
+	require.Nil(t, Binary.Unmarshal(body, &raw))
+	assert.Equal(t, []byte{0, 1, 2}, raw)
+
+	_, err = Text.Marshal(42)
+	assert.Error(t, err)
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	registry := NewRegistry(JSON)
+
+	c, err := registry.Lookup("application/json; charset=utf-8")
+	require.Nil(t, err)
+	assert.Equal(t, JSON, c)
+
+	_, err = registry.Lookup("application/msgpack")
+	assert.Error(t, err)
+}
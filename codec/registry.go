@@ -0,0 +1,62 @@
+package codec
+
+import (
+	"fmt"
+	"mime"
+	"sync"
+)
+
+// Registry resolves codecs by the Content-Type attribute of messages
+type Registry struct {
+	codecs map[string]Codec
+	mx     sync.RWMutex
+}
+
+func NewRegistry(codecs ...Codec) *Registry {
+	r := &Registry{codecs: map[string]Codec{}}
+	r.Register(codecs...)
+
+	return r
+}
+
+// DefaultRegistry contains all codecs shipped with the library
+func DefaultRegistry() *Registry {
+	return NewRegistry(JSON, Protobuf, MessagePack, Text, Binary)
+}
+
+func (r *Registry) Register(codecs ...Codec) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	for _, c := range codecs {
+		r.codecs[c.ContentType()] = c
+	}
+}
+
+// Lookup returns the codec for the content type, parameters like charset are ignored
+func (r *Registry) Lookup(contentType string) (Codec, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	c, ok := r.codecs[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("codec: no codec registered for content type '%s'", contentType)
+	}
+
+	return c, nil
+}
+
+// Unmarshal decodes the body with the codec registered for the content type
+func (r *Registry) Unmarshal(contentType string, body string, v interface{}) error {
+	c, err := r.Lookup(contentType)
+	if err != nil {
+		return err
+	}
+
+	return c.Unmarshal(body, v)
+}
@@ -0,0 +1,92 @@
+package codec
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// MessageTyper can be implemented by messages to name their Message-Type
+type MessageTyper interface {
+	MessageType() string
+}
+
+// TypeRegistry maps Go types to message type names and back, pointer and value types share a name
+type TypeRegistry struct {
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+	mx    sync.RWMutex
+}
+
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		names: map[reflect.Type]string{},
+		types: map[string]reflect.Type{},
+	}
+}
+
+// Register maps the type of prototype, e.g. Product{} or &Product{}, to the name
+func (r *TypeRegistry) Register(name string, prototype interface{}) {
+	t := baseType(reflect.TypeOf(prototype))
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.names[t] = name
+	r.types[name] = t
+}
+
+// TypeName names the type of the message by, in this order, its MessageType method, the registry and the fully qualified Go type name.
+// It returns false if none applies, e.g. for maps, slices or builtin types.
+func (r *TypeRegistry) TypeName(message interface{}) (string, bool) {
+	if typer, ok := message.(MessageTyper); ok {
+		if name := typer.MessageType(); name != "" {
+			return name, true
+		}
+	}
+
+	if message == nil {
+		return "", false
+	}
+	t := baseType(reflect.TypeOf(message))
+
+	if r != nil {
+		r.mx.RLock()
+		name, ok := r.names[t]
+		r.mx.RUnlock()
+		if ok {
+			return name, true
+		}
+	}
+
+	if t.Name() == "" || t.PkgPath() == "" {
+		return "", false
+	}
+
+	return t.PkgPath() + "." + t.Name(), true
+}
+
+// New returns a pointer to a new value of the type registered for the name, a nil registry has no types
+func (r *TypeRegistry) New(name string) (interface{}, error) {
+	if r == nil {
+		return nil, fmt.Errorf("codec: no type registered for message type '%s'", name)
+	}
+
+	r.mx.RLock()
+	t, ok := r.types[name]
+	r.mx.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("codec: no type registered for message type '%s'", name)
+	}
+
+	return reflect.New(t).Interface(), nil
+}
+
+func baseType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,51 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedEvent struct{}
+
+func (typedEvent) MessageType() string {
+	return "typed"
+}
+
+func TestTypeRegistry_TypeName(t *testing.T) {
+	registry := NewTypeRegistry()
+	registry.Register("product", &product{})
+
+	name, ok := registry.TypeName(typedEvent{})
+	assert.True(t, ok)
+	assert.Equal(t, "typed", name)
+
+	name, ok = registry.TypeName(&product{})
+	assert.True(t, ok)
+	assert.Equal(t, "product", name)
+
+	name, ok = NewTypeRegistry().TypeName(product{})
+	assert.True(t, ok)
+	assert.Equal(t, "git.limango.tech/shop-catalog/libraries/sqs-queue.git/codec.product", name)
+
+	_, ok = registry.TypeName(map[string]string{})
+	assert.False(t, ok)
+	_, ok = registry.TypeName(nil)
+	assert.False(t, ok)
+}
+
+func TestTypeRegistry_New(t *testing.T) {
+	registry := NewTypeRegistry()
+	registry.Register("product", product{})
+
+	v, err := registry.New("product")
+	require.Nil(t, err)
+	assert.IsType(t, &product{}, v)
+
+	_, err = registry.New("unknown")
+	assert.Error(t, err)
+
+	_, err = (*TypeRegistry)(nil).New("product")
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// EncodingAttribute is the message attribute naming the codec a body got compressed with.
+// Compressed bodies are always base64 encoded as SQS only accepts text.
+const EncodingAttribute = "Content-Encoding"
+
+type Codec interface {
+	Name() string
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	Gzip Codec = gzipCodec{}
+	Zstd Codec = zstdCodec{}
+)
+
+var codecs = map[string]Codec{
+	Gzip.Name(): Gzip,
+	Zstd.Name(): Zstd,
+}
+
+// Lookup returns the codec registered for the Content-Encoding value
+func Lookup(name string) (Codec, error) {
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("compression: unsupported content encoding '%s'", name)
+	}
+
+	return codec, nil
+}
+
+// Encode compresses the body and encodes it to base64
+func Encode(codec Codec, body string) (string, error) {
+	compressed, err := codec.Compress([]byte(body))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(compressed), nil
+}
+
+// Decode reverts Encode
+func Decode(codec Codec, body string) (string, error) {
+	compressed, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := codec.Decompress(compressed)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) Name() string {
+	return "gzip"
+}
+
+func (gzipCodec) Compress(data []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gzipCodec) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+type zstdCodec struct{}
+
+func (zstdCodec) Name() string {
+	return "zstd"
+}
+
+func (zstdCodec) Compress(data []byte) ([]byte, error) {
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer w.Close()
+
+	return w.EncodeAll(data, nil), nil
+}
+
+func (zstdCodec) Decompress(data []byte) ([]byte, error) {
+	r, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return r.DecodeAll(data, nil)
+}
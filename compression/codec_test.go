@@ -0,0 +1,32 @@
+package compression
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	body := strings.Repeat(`{"foo":"bar","baz":[1,2,3]}`, 100)
+
+	for _, codec := range []Codec{Gzip, Zstd} {
+		encoded, err := Encode(codec, body)
+		require.Nil(t, err, codec.Name())
+		assert.Less(t, len(encoded), len(body), codec.Name())
+
+		decoded, err := Decode(codec, encoded)
+		require.Nil(t, err, codec.Name())
+		assert.Equal(t, body, decoded, codec.Name())
+	}
+}
+
+func TestLookup(t *testing.T) {
+	codec, err := Lookup("zstd")
+	require.Nil(t, err)
+	assert.Equal(t, Zstd, codec)
+
+	_, err = Lookup("br")
+	assert.Error(t, err)
+}
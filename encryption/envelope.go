@@ -0,0 +1,115 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	AlgorithmAttribute = "Encryption-Algorithm"
+	KeyIDAttribute     = "Encryption-Key-Id"
+	DataKeyAttribute   = "Encryption-Data-Key"
+)
+
+// AlgorithmAESGCM is AES-256-GCM with the 12 byte nonce prepended to the ciphertext. The key id and the associated
+// data of the envelope are authenticated as additional data, envelopes cannot be moved to other keys or messages.
+const AlgorithmAESGCM = "AES-256-GCM"
+
+const dataKeySize = 32
+
+// Envelope is an encrypted payload together with everything except the master key needed to decrypt it
+type Envelope struct {
+	Algorithm    string
+	KeyID        string
+	EncryptedKey []byte
+	Ciphertext   []byte
+	// AssociatedData is authenticated but neither encrypted nor transported, Open needs the same data as Seal
+	AssociatedData []byte
+}
+
+// Seal encrypts the plaintext with a fresh data key of the provider, binding the key id and associatedData to it
+func Seal(ctx context.Context, provider KeyProvider, plaintext []byte, associatedData []byte) (Envelope, error) {
+	key, err := provider.GenerateDataKey(ctx)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	envelope := Envelope{
+		Algorithm:      AlgorithmAESGCM,
+		KeyID:          key.KeyID,
+		EncryptedKey:   key.Encrypted,
+		AssociatedData: associatedData,
+	}
+
+	envelope.Ciphertext, err = seal(key.Plaintext, plaintext, envelope.additionalData())
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return envelope, nil
+}
+
+// Open decrypts an envelope created by Seal, it needs the associated data Seal was given
+func Open(ctx context.Context, provider KeyProvider, envelope Envelope) ([]byte, error) {
+	if envelope.Algorithm != AlgorithmAESGCM {
+		return nil, fmt.Errorf("encryption: unsupported algorithm '%s'", envelope.Algorithm)
+	}
+
+	key, err := provider.DecryptDataKey(ctx, envelope.KeyID, envelope.EncryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(key, envelope.Ciphertext, envelope.additionalData())
+}
+
+// additionalData returns the length prefixed key id followed by the associated data
+func (e Envelope) additionalData() []byte {
+	data := binary.BigEndian.AppendUint32(nil, uint32(len(e.KeyID)))
+	data = append(data, e.KeyID...)
+
+	return append(data, e.AssociatedData...)
+}
+
+func seal(key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key []byte, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("encryption: ciphertext too short")
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
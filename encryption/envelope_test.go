@@ -0,0 +1,104 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	oldKey = bytes.Repeat([]byte{1}, 32)
+	newKey = bytes.Repeat([]byte{2}, 32)
+)
+
+func TestSealOpen(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewStaticKeyProvider("foo", map[string][]byte{"foo": oldKey})
+	require.Nil(t, err)
+
+	envelope, err := Seal(ctx, provider, []byte("foo bar baz"), []byte("order"))
+	require.Nil(t, err)
+	assert.Equal(t, AlgorithmAESGCM, envelope.Algorithm)
+	assert.Equal(t, "foo", envelope.KeyID)
+	assert.NotContains(t, string(envelope.Ciphertext), "foo bar baz")
+
+	plaintext, err := Open(ctx, provider, envelope)
+	require.Nil(t, err)
+	assert.Equal(t, "foo bar baz", string(plaintext))
+
+	otherType, otherKey := envelope, envelope
+	otherType.AssociatedData = []byte("invoice")
+	otherKey.KeyID = "bar"
+	for _, tampered := range []Envelope{otherType, otherKey} {
+		_, err = Open(ctx, provider, tampered)
+		assert.Error(t, err)
+	}
+
+	envelope.Ciphertext[len(envelope.Ciphertext)-1] ^= 1
+	_, err = Open(ctx, provider, envelope)
+	assert.Error(t, err)
+}
+
+func TestStaticKeyProvider_Rotation(t *testing.T) {
+	ctx := context.Background()
+	before, err := NewStaticKeyProvider("old", map[string][]byte{"old": oldKey})
+	require.Nil(t, err)
+	after, err := NewStaticKeyProvider("new", map[string][]byte{"old": oldKey, "new": newKey})
+	require.Nil(t, err)
+
+	envelope, err := Seal(ctx, before, []byte("foo"), nil)
+	require.Nil(t, err)
+
+	plaintext, err := Open(ctx, after, envelope)
+	require.Nil(t, err)
+	assert.Equal(t, "foo", string(plaintext))
+
+	envelope, err = Seal(ctx, after, []byte("bar"), nil)
+	require.Nil(t, err)
+	assert.Equal(t, "new", envelope.KeyID)
+
+	_, err = Open(ctx, before, envelope)
+	assert.Error(t, err)
+}
+
+func TestNewStaticKeyProviderErr(t *testing.T) {
+	_, err := NewStaticKeyProvider("foo", map[string][]byte{"foo": []byte("short")})
+	assert.Error(t, err)
+
+	_, err = NewStaticKeyProvider("bar", map[string][]byte{"foo": oldKey})
+	assert.Error(t, err)
+}
+
+type MockKMS struct {
+	master []byte
+}
+
+func (m *MockKMS) GenerateDataKey(_ context.Context, input *kms.GenerateDataKeyInput, _ ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error) {
+	plaintext := bytes.Repeat([]byte{3}, 32)
+	blob, err := seal(m.master, plaintext, nil)
+
+	return &kms.GenerateDataKeyOutput{KeyId: input.KeyId, Plaintext: plaintext, CiphertextBlob: blob}, err
+}
+
+func (m *MockKMS) Decrypt(_ context.Context, input *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	plaintext, err := open(m.master, input.CiphertextBlob, nil)
+
+	return &kms.DecryptOutput{KeyId: input.KeyId, Plaintext: plaintext}, err
+}
+
+func TestKMSKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := NewKMSKeyProvider(&MockKMS{master: oldKey}, "alias/foo")
+
+	envelope, err := Seal(ctx, provider, []byte("foo"), nil)
+	require.Nil(t, err)
+	assert.Equal(t, "alias/foo", envelope.KeyID)
+
+	plaintext, err := Open(ctx, provider, envelope)
+	require.Nil(t, err)
+	assert.Equal(t, "foo", string(plaintext))
+}
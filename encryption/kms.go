@@ -0,0 +1,57 @@
+package encryption
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmsTypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// KMSAPI is the minimum interface of the kms client required by KMSKeyProvider
+type KMSAPI interface {
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KMSKeyProvider lets KMS generate and unwrap the data keys, rotation of the master key is handled by KMS itself
+type KMSKeyProvider struct {
+	client KMSAPI
+	keyID  string
+}
+
+// NewKMSKeyProvider creates a provider encrypting with the KMS key given by id, ARN or alias
+func NewKMSKeyProvider(client KMSAPI, keyID string) *KMSKeyProvider {
+	return &KMSKeyProvider{
+		client: client,
+		keyID:  keyID,
+	}
+}
+
+func (p *KMSKeyProvider) GenerateDataKey(ctx context.Context) (DataKey, error) {
+	output, err := p.client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(p.keyID),
+		KeySpec: kmsTypes.DataKeySpecAes256,
+	})
+	if err != nil {
+		return DataKey{}, err
+	}
+
+	return DataKey{
+		KeyID:     aws.ToString(output.KeyId),
+		Plaintext: output.Plaintext,
+		Encrypted: output.CiphertextBlob,
+	}, nil
+}
+
+func (p *KMSKeyProvider) DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	output, err := p.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(keyID),
+		CiphertextBlob: encrypted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Plaintext, nil
+}
@@ -0,0 +1,17 @@
+package encryption
+
+import "context"
+
+// DataKey is a key used to encrypt a single message, Encrypted is the key wrapped by the master key KeyID
+type DataKey struct {
+	KeyID     string
+	Plaintext []byte
+	Encrypted []byte
+}
+
+// KeyProvider manages the master keys wrapping the data keys.
+// For rotation a provider encrypts with its current master key but has to keep decrypting with former ones.
+type KeyProvider interface {
+	GenerateDataKey(ctx context.Context) (DataKey, error)
+	DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error)
+}
@@ -0,0 +1,58 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+// StaticKeyProvider wraps data keys locally with static AES-256 master keys.
+// Keys are rotated by adding a new key, making it current and keeping the former ones until no message uses them anymore.
+type StaticKeyProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+func NewStaticKeyProvider(currentKeyID string, keys map[string][]byte) (*StaticKeyProvider, error) {
+	for id, key := range keys {
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("encryption: master key '%s' must be %d bytes", id, dataKeySize)
+		}
+	}
+
+	if _, ok := keys[currentKeyID]; !ok {
+		return nil, fmt.Errorf("encryption: unknown current master key '%s'", currentKeyID)
+	}
+
+	return &StaticKeyProvider{
+		current: currentKeyID,
+		keys:    keys,
+	}, nil
+}
+
+func (p *StaticKeyProvider) GenerateDataKey(_ context.Context) (DataKey, error) {
+	plaintext := make([]byte, dataKeySize)
+	if _, err := rand.Read(plaintext); err != nil {
+		return DataKey{}, err
+	}
+
+	encrypted, err := seal(p.keys[p.current], plaintext, nil)
+	if err != nil {
+		return DataKey{}, err
+	}
+
+	return DataKey{
+		KeyID:     p.current,
+		Plaintext: plaintext,
+		Encrypted: encrypted,
+	}, nil
+}
+
+func (p *StaticKeyProvider) DecryptDataKey(_ context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("encryption: unknown master key '%s'", keyID)
+	}
+
+	return open(key, encrypted, nil)
+}
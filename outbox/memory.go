@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore keeps the outbox in memory, it is meant for tests with a single relay as it does not claim records
+type MemoryStore struct {
+	records []Record
+	sent    map[string]bool
+	mx      sync.Mutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sent: map[string]bool{}}
+}
+
+func (s *MemoryStore) Add(_ context.Context, record Record) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.records = append(s.records, record)
+
+	return nil
+}
+
+func (s *MemoryStore) Pending(_ context.Context, limit int) ([]Record, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	pending := []Record{}
+	for _, record := range s.records {
+		if len(pending) >= limit {
+			break
+		}
+		if !s.sent[record.ID] {
+			pending = append(pending, record)
+		}
+	}
+
+	return pending, nil
+}
+
+func (s *MemoryStore) MarkSent(_ context.Context, ids []string) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	for _, id := range ids {
+		s.sent[id] = true
+	}
+
+	return nil
+}
+
+// Records returns all records added, sent or not
+func (s *MemoryStore) Records() []Record {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	return append([]Record{}, s.records...)
+}
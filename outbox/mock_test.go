@@ -0,0 +1,46 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+type MockClient struct {
+	batches  []*sqs.SendMessageBatchInput
+	failBody string
+	sendErr  error
+	mx       sync.Mutex
+}
+
+func (m *MockClient) SendMessage(_ context.Context, _ *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	return &sqs.SendMessageOutput{MessageId: aws.String("foo")}, m.sendErr
+}
+
+func (m *MockClient) SendMessageBatch(_ context.Context, input *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.batches = append(m.batches, input)
+	if m.sendErr != nil {
+		return nil, m.sendErr
+	}
+
+	output := &sqs.SendMessageBatchOutput{}
+	for _, entry := range input.Entries {
+		if aws.ToString(entry.MessageBody) == m.failBody {
+			output.Failed = append(output.Failed, types.BatchResultErrorEntry{Id: entry.Id, Code: aws.String("InternalError"), Message: aws.String("foo")})
+			continue
+		}
+		output.Successful = append(output.Successful, types.SendMessageBatchResultEntry{Id: entry.Id, MessageId: entry.Id})
+	}
+
+	return output, nil
+}
+
+func (m *MockClient) GetQueueUrl(context.Context, *sqs.GetQueueUrlInput, ...func(o *sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+	return &sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://foo.bar/baz")}, nil
+}
@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/publish"
+	"github.com/gofrs/uuid"
+)
+
+// Publisher writes messages to the outbox instead of sending them, the Relay sends them later
+type Publisher struct {
+	publisher *publish.Publisher
+}
+
+// NewPublisher prepares messages with the publisher, so they get parsed, validated, compressed, encrypted
+// and offloaded as if published directly
+func NewPublisher(publisher *publish.Publisher) *Publisher {
+	return &Publisher{publisher: publisher}
+}
+
+// Publish prepares the message and adds it with the writer, pass a writer bound to the transaction of the
+// changes the message is about to publish both atomically. Preparing is not part of the transaction: an Encrypter
+// calls its key provider, e.g. KMS, and an Offloader stores large bodies, payloads of rolled back transactions
+// stay in the blob store till its lifecycle removes them.
+func (p *Publisher) Publish(ctx context.Context, w Writer, message interface{}, opts ...publish.PublishOption) error {
+	input, err := p.publisher.Prepare(ctx, message, opts...)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	return w.Add(ctx, Record{
+		ID:        id.String(),
+		Input:     input,
+		CreatedAt: time.Now().UTC(),
+	})
+}
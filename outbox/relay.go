@@ -0,0 +1,94 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/publish"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/labstack/gommon/log"
+)
+
+// Relay sends pending outbox records through the publisher and marks them sent.
+// Delivery is at least once: records sent but not marked, e.g. after a crash, are sent again.
+type Relay struct {
+	store     Store
+	publisher *publish.Publisher
+	// BatchSize is the maximum number of records sent per run
+	BatchSize int
+	// Interval is the wait between runs which found no pending records or failed
+	Interval time.Duration
+}
+
+func NewRelay(store Store, publisher *publish.Publisher) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		BatchSize: 100,
+		Interval:  time.Second,
+	}
+}
+
+// Start relays records till the context is done, runs follow each other immediately while records are pending
+func (r *Relay) Start(ctx context.Context) {
+	for {
+		sent, err := r.RelayOnce(ctx)
+		if err != nil {
+			log.Errorf("outbox relay: %s", err)
+		}
+
+		if sent > 0 && err == nil {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.Interval):
+		}
+	}
+}
+
+// RelayOnce sends one batch of pending records and returns how many were sent.
+// Records failed to send stay pending, the error of the publisher is returned along the count of sent ones.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	records, err := r.store.Pending(ctx, r.BatchSize)
+	if err != nil || len(records) == 0 {
+		return 0, err
+	}
+
+	inputs := []*sqs.SendMessageInput{}
+	for _, record := range records {
+		inputs = append(inputs, record.Input)
+	}
+
+	_, publishErr := r.publisher.PublishPrepared(ctx, inputs)
+	failed := map[int]bool{}
+	if publishErr != nil {
+		var partial publish.PartialError
+		if !errors.As(publishErr, &partial) {
+			return 0, publishErr
+		}
+
+		for _, f := range partial.Failures {
+			failed[f.Index] = true
+		}
+	}
+
+	sent := []string{}
+	for i, record := range records {
+		if !failed[i] {
+			sent = append(sent, record.ID)
+		}
+	}
+
+	if err = r.store.MarkSent(ctx, sent); err != nil {
+		return 0, errors.Join(publishErr, err)
+	}
+
+	return len(sent), publishErr
+}
@@ -0,0 +1,95 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/publish"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPublisher(t *testing.T, client *MockClient) *publish.Publisher {
+	publisher, err := publish.NewPublisher(publish.PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	return publisher
+}
+
+func TestPublisher_PublishWritesPreparedInput(t *testing.T) {
+	client := &MockClient{}
+	store := NewMemoryStore()
+
+	err := NewPublisher(newPublisher(t, client)).Publish(context.Background(), store, "bar", publish.WithStringAttribute("foo", "baz"))
+	require.Nil(t, err)
+
+	records := store.Records()
+	require.Len(t, records, 1)
+	assert.NotEmpty(t, records[0].ID)
+	assert.Equal(t, "bar", aws.ToString(records[0].Input.MessageBody))
+	assert.Equal(t, "https://foo.bar/baz", aws.ToString(records[0].Input.QueueUrl))
+	assert.Equal(t, "baz", aws.ToString(records[0].Input.MessageAttributes["foo"].StringValue))
+	assert.Empty(t, client.batches)
+}
+
+func TestRelay_RelayOnceMarksSent(t *testing.T) {
+	client := &MockClient{}
+	publisher := newPublisher(t, client)
+	store := NewMemoryStore()
+	for _, body := range []string{"foo", "bar", "baz"} {
+		require.Nil(t, NewPublisher(publisher).Publish(context.Background(), store, body))
+	}
+
+	relay := NewRelay(store, publisher)
+	relay.BatchSize = 2
+
+	sent, err := relay.RelayOnce(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, 2, sent)
+
+	sent, err = relay.RelayOnce(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, 1, sent)
+
+	pending, err := store.Pending(context.Background(), 10)
+	require.Nil(t, err)
+	assert.Empty(t, pending)
+	require.Len(t, client.batches, 2)
+}
+
+func TestRelay_RelayOnceKeepsFailedPending(t *testing.T) {
+	client := &MockClient{failBody: "bar"}
+	publisher := newPublisher(t, client)
+	store := NewMemoryStore()
+	for _, body := range []string{"foo", "bar", "baz"} {
+		require.Nil(t, NewPublisher(publisher).Publish(context.Background(), store, body))
+	}
+
+	sent, err := NewRelay(store, publisher).RelayOnce(context.Background())
+
+	require.IsType(t, publish.PartialError{}, err)
+	assert.Equal(t, 2, sent)
+
+	pending, err := store.Pending(context.Background(), 10)
+	require.Nil(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "bar", aws.ToString(pending[0].Input.MessageBody))
+}
+
+func TestRelay_RelayOnceKeepsAllPendingOnRequestError(t *testing.T) {
+	client := &MockClient{sendErr: errors.New("foo")}
+	publisher := newPublisher(t, client)
+	store := NewMemoryStore()
+	require.Nil(t, NewPublisher(publisher).Publish(context.Background(), store, "bar"))
+
+	sent, err := NewRelay(store, publisher).RelayOnce(context.Background())
+
+	assert.EqualError(t, err, "foo")
+	assert.Equal(t, 0, sent)
+
+	pending, err := store.Pending(context.Background(), 10)
+	require.Nil(t, err)
+	assert.Len(t, pending, 1)
+}
@@ -0,0 +1,180 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// Placeholder returns the bind parameter for the n-th argument of a query, starting at 1
+type Placeholder func(n int) string
+
+// QuestionPlaceholder is used by MySQL and SQLite
+func QuestionPlaceholder(int) string {
+	return "?"
+}
+
+// DollarPlaceholder is used by PostgreSQL
+func DollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// DefaultLease is the time records returned by Pending are claimed for
+const DefaultLease = time.Minute
+
+// SQLStore keeps the outbox in a database table with the columns
+//
+//	id            VARCHAR(36) PRIMARY KEY
+//	payload       TEXT NOT NULL
+//	created_at    TIMESTAMP NOT NULL
+//	sent_at       TIMESTAMP NULL
+//	claimed_until TIMESTAMP NULL
+//
+// the payload holds the JSON encoded SendMessageInput.
+type SQLStore struct {
+	db          *sql.DB
+	table       string
+	Placeholder Placeholder
+	// Lease is the time a relay has to send the records returned by Pending, other relays skip them till then
+	Lease time.Duration
+	// LockClause locks the selected rows while they are claimed, set it empty for databases without row locks like SQLite
+	LockClause string
+}
+
+func NewSQLStore(db *sql.DB, table string) *SQLStore {
+	return &SQLStore{
+		db:          db,
+		table:       table,
+		Placeholder: QuestionPlaceholder,
+		Lease:       DefaultLease,
+		LockClause:  "FOR UPDATE SKIP LOCKED",
+	}
+}
+
+// execer is implemented by sql.DB and sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// sqlWriter adds records within a transaction
+type sqlWriter struct {
+	store *SQLStore
+	tx    execer
+}
+
+func (w sqlWriter) Add(ctx context.Context, record Record) error {
+	return w.store.insert(ctx, w.tx, record)
+}
+
+// Tx returns a writer adding records within the transaction
+func (s *SQLStore) Tx(tx *sql.Tx) Writer {
+	return sqlWriter{store: s, tx: tx}
+}
+
+// Add adds the record outside any transaction
+func (s *SQLStore) Add(ctx context.Context, record Record) error {
+	return s.insert(ctx, s.db, record)
+}
+
+func (s *SQLStore) insert(ctx context.Context, db execer, record Record) error {
+	payload, err := json.Marshal(record.Input)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (id, payload, created_at) VALUES (%s, %s, %s)",
+		s.table, s.Placeholder(1), s.Placeholder(2), s.Placeholder(3),
+	)
+	_, err = db.ExecContext(ctx, query, record.ID, string(payload), record.CreatedAt)
+
+	return err
+}
+
+// Pending claims up to limit unsent records for Lease, records claimed by other relays are skipped.
+// Records not marked sent are returned again once their claim expired.
+func (s *SQLStore) Pending(ctx context.Context, limit int) ([]Record, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	records, err := s.unclaimed(ctx, tx, now, limit)
+	if err != nil || len(records) == 0 {
+		return records, err
+	}
+
+	placeholders := []string{}
+	args := []any{now.Add(s.Lease)}
+	for i, record := range records {
+		placeholders = append(placeholders, s.Placeholder(i+2))
+		args = append(args, record.ID)
+	}
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET claimed_until = %s WHERE id IN (%s)",
+		s.table, s.Placeholder(1), strings.Join(placeholders, ", "),
+	)
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+
+	return records, tx.Commit()
+}
+
+func (s *SQLStore) unclaimed(ctx context.Context, tx *sql.Tx, now time.Time, limit int) ([]Record, error) {
+	query := fmt.Sprintf(
+		"SELECT id, payload, created_at FROM %s WHERE sent_at IS NULL AND (claimed_until IS NULL OR claimed_until < %s) ORDER BY created_at LIMIT %d %s",
+		s.table, s.Placeholder(1), limit, s.LockClause,
+	)
+	rows, err := tx.QueryContext(ctx, strings.TrimSpace(query), now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []Record{}
+	for rows.Next() {
+		record := Record{Input: &sqs.SendMessageInput{}}
+		payload := ""
+		if err = rows.Scan(&record.ID, &payload, &record.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(payload), record.Input); err != nil {
+			return nil, fmt.Errorf("outbox record %s: %w", record.ID, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+func (s *SQLStore) MarkSent(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := []string{}
+	args := []any{time.Now().UTC()}
+	for i, id := range ids {
+		placeholders = append(placeholders, s.Placeholder(i+2))
+		args = append(args, id)
+	}
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET sent_at = %s WHERE id IN (%s)",
+		s.table, s.Placeholder(1), strings.Join(placeholders, ", "),
+	)
+	_, err := s.db.ExecContext(ctx, query, args...)
+
+	return err
+}
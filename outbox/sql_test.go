@@ -0,0 +1,140 @@
+package outbox
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLStore_TxAddsWithinTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.Nil(t, err)
+	defer db.Close()
+
+	record := Record{
+		ID:        "foo",
+		Input:     &sqs.SendMessageInput{QueueUrl: aws.String("https://foo.bar/baz"), MessageBody: aws.String("bar")},
+		CreatedAt: time.Now(),
+	}
+	payload, err := json.Marshal(record.Input)
+	require.Nil(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO outbox \(id, payload, created_at\) VALUES \(\$1, \$2, \$3\)`).
+		WithArgs("foo", string(payload), record.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectRollback()
+
+	store := NewSQLStore(db, "outbox")
+	store.Placeholder = DollarPlaceholder
+
+	tx, err := db.Begin()
+	require.Nil(t, err)
+	require.Nil(t, store.Tx(tx).Add(context.Background(), record))
+	require.Nil(t, tx.Rollback())
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLStore_PendingDecodesInput(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.Nil(t, err)
+	defer db.Close()
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:    aws.String("https://foo.bar/baz"),
+		MessageBody: aws.String("bar"),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"foo": {DataType: aws.String("Binary"), BinaryValue: []byte("baz")},
+		},
+	}
+	payload, err := json.Marshal(input)
+	require.Nil(t, err)
+	createdAt := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, payload, created_at FROM outbox WHERE sent_at IS NULL AND \(claimed_until IS NULL OR claimed_until < \?\) ORDER BY created_at LIMIT 5 FOR UPDATE SKIP LOCKED`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payload", "created_at"}).AddRow("foo", string(payload), createdAt))
+	mock.ExpectExec(`UPDATE outbox SET claimed_until = \? WHERE id IN \(\?\)`).
+		WithArgs(sqlmock.AnyArg(), "foo").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	records, err := NewSQLStore(db, "outbox").Pending(context.Background(), 5)
+	require.Nil(t, err)
+
+	require.Len(t, records, 1)
+	assert.Equal(t, "foo", records[0].ID)
+	assert.Equal(t, createdAt, records[0].CreatedAt)
+	assert.Equal(t, "bar", aws.ToString(records[0].Input.MessageBody))
+	assert.Equal(t, []byte("baz"), records[0].Input.MessageAttributes["foo"].BinaryValue)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLStore_PendingClaimsForLease(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.Nil(t, err)
+	defer db.Close()
+
+	store := NewSQLStore(db, "outbox")
+	store.Placeholder = DollarPlaceholder
+	store.Lease = time.Hour
+	store.LockClause = ""
+
+	before := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, payload, created_at FROM outbox WHERE sent_at IS NULL AND \(claimed_until IS NULL OR claimed_until < \$1\) ORDER BY created_at LIMIT 2$`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payload", "created_at"}).AddRow("foo", "{}", before).AddRow("bar", "{}", before))
+	mock.ExpectExec(`UPDATE outbox SET claimed_until = \$1 WHERE id IN \(\$2, \$3\)`).
+		WithArgs(leaseUntil{after: before.Add(time.Hour)}, "foo", "bar").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	records, err := store.Pending(context.Background(), 2)
+	require.Nil(t, err)
+	assert.Len(t, records, 2)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, payload, created_at FROM outbox`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "payload", "created_at"}))
+	mock.ExpectRollback()
+
+	records, err = store.Pending(context.Background(), 2)
+	require.Nil(t, err)
+	assert.Empty(t, records)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+// leaseUntil matches claim expiries not before after
+type leaseUntil struct {
+	after time.Time
+}
+
+func (l leaseUntil) Match(v driver.Value) bool {
+	until, ok := v.(time.Time)
+	return ok && !until.Before(l.after)
+}
+
+func TestSQLStore_MarkSent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.Nil(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE outbox SET sent_at = \? WHERE id IN \(\?, \?\)`).
+		WithArgs(sqlmock.AnyArg(), "foo", "bar").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	require.Nil(t, NewSQLStore(db, "outbox").MarkSent(context.Background(), []string{"foo", "bar"}))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
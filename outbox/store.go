@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// Record is a prepared message waiting in the outbox to be sent
+type Record struct {
+	ID        string
+	Input     *sqs.SendMessageInput
+	CreatedAt time.Time
+}
+
+// Writer adds records to the outbox, writers bound to a transaction only persist them if it commits
+type Writer interface {
+	Add(ctx context.Context, record Record) error
+}
+
+// Store provides the records not sent yet to the relay
+type Store interface {
+	// Pending returns up to limit unsent records, oldest first. Stores shared by several relays claim the records,
+	// so concurrent calls do not return the same ones.
+	Pending(ctx context.Context, limit int) ([]Record, error)
+	MarkSent(ctx context.Context, ids []string) error
+}
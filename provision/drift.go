@@ -0,0 +1,92 @@
+package provision
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrDrift is wrapped by errors reporting queues differing from their spec
+var ErrDrift = errors.New("queue drifted from its spec")
+
+// immutableAttributes cannot be changed after the queue got created
+var immutableAttributes = map[string]bool{
+	"FifoQueue": true,
+}
+
+// Drift is an attribute of an existing queue differing from the spec
+type Drift struct {
+	Attribute string
+	Expected  string
+	Actual    string
+}
+
+func (d Drift) String() string {
+	return fmt.Sprintf("%s is %q instead of %q", d.Attribute, d.Actual, d.Expected)
+}
+
+// DriftError reports the drifts of a queue which were not fixed
+type DriftError struct {
+	QueueURL string
+	Drifts   []Drift
+}
+
+func (e *DriftError) Error() string {
+	drifts := []string{}
+	for _, d := range e.Drifts {
+		drifts = append(drifts, d.String())
+	}
+
+	return fmt.Sprintf("%s: queue %s: %s", ErrDrift, e.QueueURL, strings.Join(drifts, ", "))
+}
+
+func (e *DriftError) Unwrap() error {
+	return ErrDrift
+}
+
+// compare returns the drifts of the actual attributes from the expected ones, ordered by attribute
+func compare(expected map[string]string, actual map[string]string) []Drift {
+	names := []string{}
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	drifts := []Drift{}
+	for _, name := range names {
+		if !equalAttributes(name, expected[name], actual[name]) {
+			drifts = append(drifts, Drift{Attribute: name, Expected: expected[name], Actual: actual[name]})
+		}
+	}
+
+	return drifts
+}
+
+func equalAttributes(name string, expected string, actual string) bool {
+	switch name {
+	case "RedrivePolicy":
+		return equalRedrivePolicies(expected, actual)
+	case "FifoQueue", "ContentBasedDeduplication":
+		// SQS omits them for standard queues
+		if actual == "" {
+			actual = "false"
+		}
+		return strings.EqualFold(expected, actual)
+	case "Policy", "RedriveAllowPolicy":
+		return equalJSON(expected, actual)
+	default:
+		return expected == actual
+	}
+}
+
+func equalJSON(a string, b string) bool {
+	var valueA, valueB interface{}
+	if json.Unmarshal([]byte(a), &valueA) != nil || json.Unmarshal([]byte(b), &valueB) != nil {
+		return a == b
+	}
+
+	return reflect.DeepEqual(valueA, valueB)
+}
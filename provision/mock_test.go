@@ -0,0 +1,24 @@
+package provision
+
+import (
+	"context"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/sqstest"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// MockRacingClient creates queues with other attributes right before CreateQueue, like a concurrent provisioner
+type MockRacingClient struct {
+	*sqstest.Client
+	attributes map[string]string
+	created    []string
+}
+
+func (m *MockRacingClient) CreateQueue(_ context.Context, params *sqs.CreateQueueInput, _ ...func(*sqs.Options)) (*sqs.CreateQueueOutput, error) {
+	name := aws.ToString(params.QueueName)
+	m.created = append(m.created, m.Client.MustCreateQueue(name, m.attributes))
+
+	return nil, &types.QueueNameExists{Message: aws.String("queue " + name + " exists with other attributes")}
+}
@@ -0,0 +1,166 @@
+package provision
+
+import (
+	"context"
+	"errors"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/labstack/gommon/log"
+)
+
+// SQSProvisioner is the part of the SQS API needed to provision queues
+type SQSProvisioner interface {
+	utils.SQSQueueURLResolver
+	CreateQueue(ctx context.Context, params *sqs.CreateQueueInput, optFns ...func(*sqs.Options)) (*sqs.CreateQueueOutput, error)
+	GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
+	SetQueueAttributes(ctx context.Context, params *sqs.SetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.SetQueueAttributesOutput, error)
+}
+
+// Queue is a provisioned queue
+type Queue struct {
+	URL string
+	ARN string
+	// Created reports whether the queue got created, otherwise it existed already
+	Created bool
+	// Drifts are the differences of the existing queue from the spec, the mutable ones are fixed if Update is set
+	Drifts          []Drift
+	DeadLetterQueue *Queue
+}
+
+// Provisioner creates missing queues and verifies the attributes of existing ones
+type Provisioner struct {
+	// Update sets the drifted attributes of existing queues, otherwise drift is reported by a DriftError
+	Update bool
+	client SQSProvisioner
+}
+
+func NewProvisioner(client SQSProvisioner) *Provisioner {
+	return &Provisioner{client: client}
+}
+
+// EnsureQueue provisions the queue with a Provisioner only verifying existing queues
+func EnsureQueue(ctx context.Context, client SQSProvisioner, spec QueueSpec) (*Queue, error) {
+	return NewProvisioner(client).EnsureQueue(ctx, spec)
+}
+
+// EnsureQueue creates the queue and its dead-letter queue if missing and compares the attributes of existing ones with
+// the spec. Drifts not fixed are reported by a DriftError, the returned queue is complete nevertheless.
+func (p *Provisioner) EnsureQueue(ctx context.Context, spec QueueSpec) (*Queue, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p.ensure(ctx, spec)
+}
+
+func (p *Provisioner) ensure(ctx context.Context, spec QueueSpec) (*Queue, error) {
+	attributes := spec.attributes()
+
+	var dlq *Queue
+	var dlqErr error
+	if spec.DeadLetterQueue != nil {
+		dlq, dlqErr = p.ensure(ctx, spec.deadLetterSpec())
+		if dlq == nil {
+			return nil, dlqErr
+		}
+		attributes["RedrivePolicy"] = newRedrivePolicy(dlq.ARN, spec.maxReceiveCount())
+	}
+
+	queue, err := p.createOrVerify(ctx, spec.Name, attributes)
+	if queue == nil {
+		return nil, err
+	}
+	queue.DeadLetterQueue = dlq
+
+	return queue, errors.Join(dlqErr, err)
+}
+
+// createOrVerify creates the queue if it does not exist, it returns the queue with a DriftError if it has drifted
+func (p *Provisioner) createOrVerify(ctx context.Context, name string, attributes map[string]string) (*Queue, error) {
+	output, err := p.client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String(name)})
+
+	var notExists *types.QueueDoesNotExist
+	if errors.As(err, &notExists) {
+		queue, createErr := p.create(ctx, name, attributes)
+		var exists *types.QueueNameExists
+		if !errors.As(createErr, &exists) {
+			return queue, createErr
+		}
+
+		// created concurrently with other attributes
+		output, err = p.client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String(name)})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p.verify(ctx, aws.ToString(output.QueueUrl), attributes)
+}
+
+func (p *Provisioner) create(ctx context.Context, name string, attributes map[string]string) (*Queue, error) {
+	output, err := p.client.CreateQueue(ctx, &sqs.CreateQueueInput{QueueName: aws.String(name), Attributes: attributes})
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &Queue{URL: aws.ToString(output.QueueUrl), Created: true}
+	actual, err := p.attributes(ctx, queue.URL)
+	if err != nil {
+		return nil, err
+	}
+	queue.ARN = actual["QueueArn"]
+
+	log.Infof("created sqs queue %s", queue.URL)
+	return queue, nil
+}
+
+func (p *Provisioner) verify(ctx context.Context, queueURL string, attributes map[string]string) (*Queue, error) {
+	actual, err := p.attributes(ctx, queueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &Queue{URL: queueURL, ARN: actual["QueueArn"], Drifts: compare(attributes, actual)}
+	if len(queue.Drifts) == 0 {
+		return queue, nil
+	}
+
+	unfixed := []Drift{}
+	update := map[string]string{}
+	for _, d := range queue.Drifts {
+		if p.Update && !immutableAttributes[d.Attribute] {
+			update[d.Attribute] = d.Expected
+		} else {
+			unfixed = append(unfixed, d)
+		}
+	}
+
+	if len(update) > 0 {
+		_, err = p.client.SetQueueAttributes(ctx, &sqs.SetQueueAttributesInput{QueueUrl: aws.String(queueURL), Attributes: update})
+		if err != nil {
+			return nil, err
+		}
+		log.Warnf("updated drifted attributes of sqs queue %s: %v", queueURL, queue.Drifts)
+	}
+
+	if len(unfixed) > 0 {
+		return queue, &DriftError{QueueURL: queueURL, Drifts: unfixed}
+	}
+
+	return queue, nil
+}
+
+func (p *Provisioner) attributes(ctx context.Context, queueURL string) (map[string]string, error) {
+	output, err := p.client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(queueURL),
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameAll},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Attributes, nil
+}
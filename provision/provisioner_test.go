@@ -0,0 +1,118 @@
+package provision
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/sqstest"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ SQSProvisioner = (*sqstest.Client)(nil)
+
+func queueAttributes(t *testing.T, client *sqstest.Client, queueURL string) map[string]string {
+	output, err := client.GetQueueAttributes(context.Background(), &sqs.GetQueueAttributesInput{
+		QueueUrl: aws.String(queueURL), AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameAll},
+	})
+	require.Nil(t, err)
+
+	return output.Attributes
+}
+
+func TestEnsureQueue_CreatesQueueWithDeadLetterQueue(t *testing.T) {
+	client := sqstest.NewClient()
+	spec := QueueSpec{
+		Name:                      "orders.fifo",
+		FIFO:                      true,
+		ContentBasedDeduplication: true,
+		VisibilityTimeout:         time.Minute,
+		MessageRetention:          24 * time.Hour,
+		KMSMasterKeyID:            "alias/aws/sqs",
+		DeadLetterQueue:           &QueueSpec{MessageRetention: 14 * 24 * time.Hour},
+		MaxReceiveCount:           3,
+	}
+
+	queue, err := EnsureQueue(context.Background(), client, spec)
+	require.Nil(t, err)
+	assert.True(t, queue.Created)
+	require.NotNil(t, queue.DeadLetterQueue)
+	assert.True(t, queue.DeadLetterQueue.Created)
+	assert.Equal(t, "arn:aws:sqs:eu-central-1:000000000000:orders-dlq.fifo", queue.DeadLetterQueue.ARN)
+
+	attributes := queueAttributes(t, client, queue.URL)
+	assert.Equal(t, "true", attributes["FifoQueue"])
+	assert.Equal(t, "true", attributes["ContentBasedDeduplication"])
+	assert.Equal(t, "60", attributes["VisibilityTimeout"])
+	assert.Equal(t, "86400", attributes["MessageRetentionPeriod"])
+	assert.Equal(t, "alias/aws/sqs", attributes["KmsMasterKeyId"])
+	assert.JSONEq(t, `{"deadLetterTargetArn":"arn:aws:sqs:eu-central-1:000000000000:orders-dlq.fifo","maxReceiveCount":3}`, attributes["RedrivePolicy"])
+	assert.Equal(t, "1209600", queueAttributes(t, client, queue.DeadLetterQueue.URL)["MessageRetentionPeriod"])
+
+	again, err := EnsureQueue(context.Background(), client, spec)
+	require.Nil(t, err)
+	assert.False(t, again.Created)
+	assert.Empty(t, again.Drifts)
+	assert.Equal(t, queue.URL, again.URL)
+	assert.Equal(t, queue.ARN, again.ARN)
+}
+
+func TestEnsureQueue_ReportsDrift(t *testing.T) {
+	client := sqstest.NewClient()
+	client.MustCreateQueue("orders-dlq", nil)
+	queueURL := client.MustCreateQueue("orders", map[string]string{
+		"VisibilityTimeout": "30",
+		"RedrivePolicy":     `{"deadLetterTargetArn":"arn:aws:sqs:eu-central-1:000000000000:orders-dlq","maxReceiveCount":"5"}`,
+	})
+	spec := QueueSpec{Name: "orders", VisibilityTimeout: time.Minute, DeadLetterQueue: &QueueSpec{}}
+
+	queue, err := EnsureQueue(context.Background(), client, spec)
+	assert.ErrorIs(t, err, ErrDrift)
+	var drift *DriftError
+	require.ErrorAs(t, err, &drift)
+	assert.Equal(t, queueURL, drift.QueueURL)
+	assert.Equal(t, []Drift{{Attribute: "VisibilityTimeout", Expected: "60", Actual: "30"}}, drift.Drifts)
+	require.NotNil(t, queue)
+	assert.Equal(t, drift.Drifts, queue.Drifts)
+	assert.Equal(t, "30", queueAttributes(t, client, queueURL)["VisibilityTimeout"])
+
+	provisioner := NewProvisioner(client)
+	provisioner.Update = true
+	queue, err = provisioner.EnsureQueue(context.Background(), spec)
+	require.Nil(t, err)
+	assert.Len(t, queue.Drifts, 1)
+	assert.Equal(t, "60", queueAttributes(t, client, queueURL)["VisibilityTimeout"])
+
+	queue, err = EnsureQueue(context.Background(), client, spec)
+	require.Nil(t, err)
+	assert.Empty(t, queue.Drifts)
+}
+
+func TestEnsureQueue_CreatedConcurrently(t *testing.T) {
+	client := &MockRacingClient{Client: sqstest.NewClient(), attributes: map[string]string{"VisibilityTimeout": "30"}}
+	spec := QueueSpec{Name: "orders", VisibilityTimeout: time.Minute}
+
+	queue, err := EnsureQueue(context.Background(), client, spec)
+	assert.ErrorIs(t, err, ErrDrift)
+	require.NotNil(t, queue)
+	require.Len(t, client.created, 1)
+	assert.Equal(t, client.created[0], queue.URL)
+	assert.Equal(t, []Drift{{Attribute: "VisibilityTimeout", Expected: "60", Actual: "30"}}, queue.Drifts)
+}
+
+func TestQueueSpec_Validate(t *testing.T) {
+	assert.Nil(t, QueueSpec{Name: "orders", DeadLetterQueue: &QueueSpec{}}.Validate())
+	assert.ErrorIs(t, QueueSpec{}.Validate(), utils.ErrInvalidConfig)
+	assert.ErrorIs(t, QueueSpec{Name: "orders.fifo"}.Validate(), utils.ErrInvalidConfig)
+	assert.ErrorIs(t, QueueSpec{Name: "orders", ContentBasedDeduplication: true}.Validate(), utils.ErrInvalidConfig)
+	assert.ErrorIs(t, QueueSpec{Name: "orders.fifo", FIFO: true, DeadLetterQueue: &QueueSpec{Name: "orders-dlq"}}.Validate(), utils.ErrInvalidConfig)
+	assert.ErrorIs(t, QueueSpec{Name: "orders", MaxReceiveCount: 1001}.Validate(), utils.ErrInvalidConfig)
+
+	_, err := EnsureQueue(context.Background(), sqstest.NewClient(), QueueSpec{Name: "orders.fifo"})
+	assert.ErrorIs(t, err, utils.ErrInvalidConfig)
+}
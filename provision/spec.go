@@ -0,0 +1,148 @@
+package provision
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+)
+
+// DefaultMaxReceiveCount is the number of receives before messages are moved to the dead-letter queue if the spec sets none
+const DefaultMaxReceiveCount = 5
+
+const (
+	fifoSuffix       = ".fifo"
+	deadLetterSuffix = "-dlq"
+)
+
+// QueueSpec declares a queue. Attributes left at their zero value keep the defaults of SQS and are not verified.
+type QueueSpec struct {
+	// Name of the queue, names of FIFO queues end with .fifo
+	Name                      string
+	FIFO                      bool
+	ContentBasedDeduplication bool
+	VisibilityTimeout         time.Duration
+	MessageRetention          time.Duration
+	// KMSMasterKeyID enables server-side encryption with the key, e.g. alias/aws/sqs
+	KMSMasterKeyID        string
+	KMSDataKeyReusePeriod time.Duration
+	// DeadLetterQueue receives messages received more than MaxReceiveCount times, it is provisioned before the
+	// queue. Its name defaults to the name of the queue with a -dlq suffix, it is FIFO if the queue is.
+	DeadLetterQueue *QueueSpec
+	MaxReceiveCount int
+	// Attributes are further attributes, e.g. DelaySeconds or Policy, the fields of the spec take precedence
+	Attributes map[string]string
+}
+
+// Validate reports specs SQS would reject
+func (s QueueSpec) Validate() error {
+	if s.Name == "" {
+		return utils.InvalidConfig("the queue name is required")
+	}
+	if s.FIFO != strings.HasSuffix(s.Name, fifoSuffix) {
+		return utils.InvalidConfig("queue %s: FIFO has to be set exactly for queues named *%s", s.Name, fifoSuffix)
+	}
+	if s.ContentBasedDeduplication && !s.FIFO {
+		return utils.InvalidConfig("queue %s: content-based deduplication requires a FIFO queue", s.Name)
+	}
+	if s.VisibilityTimeout < 0 || s.MessageRetention < 0 || s.KMSDataKeyReusePeriod < 0 {
+		return utils.InvalidConfig("queue %s: durations must not be negative", s.Name)
+	}
+	if s.MaxReceiveCount < 0 || s.MaxReceiveCount > 1000 {
+		return utils.InvalidConfig("queue %s: MaxReceiveCount has to be between 1 and 1000", s.Name)
+	}
+
+	if s.DeadLetterQueue != nil {
+		dlq := s.deadLetterSpec()
+		if dlq.FIFO != s.FIFO {
+			return utils.InvalidConfig("queue %s: the dead-letter queue has to be of the same type as the queue", s.Name)
+		}
+		if dlq.Name == s.Name {
+			return utils.InvalidConfig("queue %s: the queue cannot be its own dead-letter queue", s.Name)
+		}
+
+		return dlq.Validate()
+	}
+
+	return nil
+}
+
+// deadLetterSpec returns the spec of the dead-letter queue with the default name and type applied
+func (s QueueSpec) deadLetterSpec() QueueSpec {
+	dlq := *s.DeadLetterQueue
+	if dlq.Name == "" {
+		dlq.Name = strings.TrimSuffix(s.Name, fifoSuffix) + deadLetterSuffix
+		if s.FIFO {
+			dlq.Name += fifoSuffix
+		}
+		dlq.FIFO = s.FIFO
+	}
+
+	return dlq
+}
+
+// attributes returns the declared attributes, the redrive policy is added once the dead-letter queue exists
+func (s QueueSpec) attributes() map[string]string {
+	attributes := map[string]string{}
+	for name, value := range s.Attributes {
+		attributes[name] = value
+	}
+
+	if s.FIFO {
+		attributes["FifoQueue"] = "true"
+		attributes["ContentBasedDeduplication"] = strconv.FormatBool(s.ContentBasedDeduplication)
+	}
+	if s.VisibilityTimeout > 0 {
+		attributes["VisibilityTimeout"] = seconds(s.VisibilityTimeout)
+	}
+	if s.MessageRetention > 0 {
+		attributes["MessageRetentionPeriod"] = seconds(s.MessageRetention)
+	}
+	if s.KMSMasterKeyID != "" {
+		attributes["KmsMasterKeyId"] = s.KMSMasterKeyID
+	}
+	if s.KMSDataKeyReusePeriod > 0 {
+		attributes["KmsDataKeyReusePeriodSeconds"] = seconds(s.KMSDataKeyReusePeriod)
+	}
+
+	return attributes
+}
+
+func (s QueueSpec) maxReceiveCount() int {
+	if s.MaxReceiveCount == 0 {
+		return DefaultMaxReceiveCount
+	}
+
+	return s.MaxReceiveCount
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(d / time.Second))
+}
+
+type redrivePolicy struct {
+	DeadLetterTargetArn string          `json:"deadLetterTargetArn"`
+	MaxReceiveCount     json.RawMessage `json:"maxReceiveCount"`
+}
+
+func newRedrivePolicy(deadLetterARN string, maxReceiveCount int) string {
+	policy, _ := json.Marshal(redrivePolicy{
+		DeadLetterTargetArn: deadLetterARN,
+		MaxReceiveCount:     json.RawMessage(strconv.Itoa(maxReceiveCount)),
+	})
+
+	return string(policy)
+}
+
+// equalRedrivePolicies compares the policies ignoring formatting, SQS accepts the receive count as number or string
+func equalRedrivePolicies(a string, b string) bool {
+	policyA, policyB := redrivePolicy{}, redrivePolicy{}
+	if json.Unmarshal([]byte(a), &policyA) != nil || json.Unmarshal([]byte(b), &policyB) != nil {
+		return a == b
+	}
+
+	return policyA.DeadLetterTargetArn == policyB.DeadLetterTargetArn &&
+		strings.Trim(string(policyA.MaxReceiveCount), `"`) == strings.Trim(string(policyB.MaxReceiveCount), `"`)
+}
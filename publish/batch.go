@@ -0,0 +1,159 @@
+package publish
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+	"github.com/labstack/gommon/log"
+)
+
+const maxEntriesPerRequest = 10
+const parallelRequests = 10
+
+// requestFailedCode marks entries of a request that failed as a whole
+const requestFailedCode = "RequestFailed"
+
+// chunkEntries splits entries into batches within the SQS limits for entry count and total payload size
+func chunkEntries(entries []types.SendMessageBatchRequestEntry) [][]types.SendMessageBatchRequestEntry {
+	chunks := [][]types.SendMessageBatchRequestEntry{}
+	chunk := []types.SendMessageBatchRequestEntry{}
+	chunkSize := 0
+
+	for _, entry := range entries {
+		size := messageSize(aws.ToString(entry.MessageBody), entry.MessageAttributes)
+
+		if len(chunk) > 0 && (len(chunk) >= maxEntriesPerRequest || chunkSize+size > MaxMessageSize) {
+			chunks = append(chunks, chunk)
+			chunk = []types.SendMessageBatchRequestEntry{}
+			chunkSize = 0
+		}
+
+		chunk = append(chunk, entry)
+		chunkSize += size
+	}
+
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// batchResult collects the outcome of the requests of a batch
+type batchResult struct {
+	total      int
+	successful []Result
+	failed     []types.BatchResultErrorEntry
+	// requestErr is the last error of a request failed as a whole
+	requestErr error
+	mx         sync.Mutex
+}
+
+func (r *batchResult) add(chunk []types.SendMessageBatchRequestEntry, output *sqs.SendMessageBatchOutput, err error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.total += len(chunk)
+
+	if err != nil {
+		r.requestErr = err
+		for _, entry := range chunk {
+			r.failed = append(r.failed, types.BatchResultErrorEntry{
+				Id:          entry.Id,
+				Code:        aws.String(requestFailedCode),
+				Message:     aws.String(err.Error()),
+				SenderFault: senderFault(err),
+			})
+		}
+		return
+	}
+
+	byID := map[string]types.SendMessageBatchRequestEntry{}
+	for _, entry := range chunk {
+		byID[aws.ToString(entry.Id)] = entry
+	}
+
+	for _, o := range output.Successful {
+		entry := byID[aws.ToString(o.Id)]
+		result := newEntryResult(o)
+		result.ChecksumErr = result.verify(aws.ToString(entry.MessageBody), entry.MessageAttributes)
+		if result.ChecksumErr != nil {
+			log.Warnf("published message %s: %s", result.MessageID, result.ChecksumErr)
+		} else {
+			log.Debugf("published message %s", result.MessageID)
+		}
+		r.successful = append(r.successful, result)
+	}
+
+	r.failed = append(r.failed, output.Failed...)
+}
+
+// senderFault tells whether a request failed by a client fault which resending cannot fix, throttling can be waited out.
+// Errors without fault, e.g. of the connection, are no sender faults.
+func senderFault(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	return apiErr.ErrorFault() == smithy.FaultClient && !throttlingCodes[apiErr.ErrorCode()]
+}
+
+// results returns the results of the published messages ordered by their index
+func (r *batchResult) results() []Result {
+	results := append([]Result{}, r.successful...)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Index < results[j].Index
+	})
+
+	return results
+}
+
+// err returns nil if all messages got published, the request error if every request failed and a PartialError
+// mapping the failures to the messages otherwise
+func (r *batchResult) err(messages []interface{}) error {
+	if len(r.failed) == 0 {
+		return nil
+	}
+
+	if len(r.failed) == r.total && r.requestFailures() == r.total {
+		return r.requestErr
+	}
+
+	return newPartialError(r.total, r.failed, messages)
+}
+
+func (r *batchResult) requestFailures() int {
+	count := 0
+	for _, f := range r.failed {
+		if aws.ToString(f.Code) == requestFailedCode {
+			count++
+		}
+	}
+
+	return count
+}
+
+// append adds the outcome of the requests of another batch
+func (r *batchResult) append(other *batchResult) {
+	r.total += other.total
+	r.successful = append(r.successful, other.successful...)
+	r.failed = append(r.failed, other.failed...)
+	if other.requestErr != nil {
+		r.requestErr = other.requestErr
+	}
+}
+
+// merge replaces the failures of retried entries by the result of the retry
+func (r *batchResult) merge(remaining []types.BatchResultErrorEntry, retry *batchResult) {
+	r.failed = append(remaining, retry.failed...)
+	r.successful = append(r.successful, retry.successful...)
+	if retry.requestErr != nil {
+		r.requestErr = retry.requestErr
+	}
+}
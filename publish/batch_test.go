@@ -0,0 +1,195 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisher_PublishBatchChunksByCount(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	messages := []interface{}{}
+	for i := 0; i < 25; i++ {
+		messages = append(messages, i)
+	}
+
+	_, err = publisher.PublishBatch(context.Background(), messages)
+	require.Nil(t, err)
+
+	require.Len(t, client.batches, 3)
+	sizes := []int{}
+	for _, batch := range client.batches {
+		sizes = append(sizes, len(batch.Entries))
+	}
+	assert.ElementsMatch(t, []int{10, 10, 5}, sizes)
+}
+
+func TestPublisher_PublishBatchToFIFOInOrder(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz.fifo", batchDelay: 10 * time.Millisecond}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz.fifo", IsFIFO: true}, client)
+	require.Nil(t, err)
+
+	messages := []interface{}{}
+	for i := 0; i < 25; i++ {
+		messages = append(messages, i)
+	}
+
+	_, err = publisher.PublishBatch(context.Background(), messages, WithMessageGroupID("foo"))
+	require.Nil(t, err)
+
+	assert.Equal(t, int32(1), client.maxInFlight)
+	bodies := []string{}
+	for _, batch := range client.batches {
+		for _, entry := range batch.Entries {
+			bodies = append(bodies, aws.ToString(entry.MessageBody))
+		}
+	}
+	require.Len(t, bodies, 25)
+	for i, body := range bodies {
+		assert.Equal(t, strconv.Itoa(i), body)
+	}
+}
+
+func TestPublisher_PublishBatchChunksBySize(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	large := strings.Repeat("x", 100*1024)
+	_, err = publisher.PublishBatch(context.Background(), []interface{}{large, large, large})
+	require.Nil(t, err)
+
+	require.Len(t, client.batches, 2)
+	sizes := []int{len(client.batches[0].Entries), len(client.batches[1].Entries)}
+	assert.ElementsMatch(t, []int{2, 1}, sizes)
+}
+
+func TestPublisher_PublishBatchMergesFailures(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	messages := []interface{}{}
+	for i := 0; i < 12; i++ {
+		messages = append(messages, "bar")
+	}
+	messages[3], messages[11] = "foo", "foo"
+
+	_, err = publisher.PublishBatch(context.Background(), messages)
+
+	require.IsType(t, PartialError{}, err)
+	assert.Equal(t, 12, err.(PartialError).Total)
+	assert.Len(t, err.(PartialError).Errors, 2)
+}
+
+func TestPublisher_PublishBatchRequestErr(t *testing.T) {
+	expectedErr := errors.New("foo bar baz")
+	client := &MockClient{queueUrl: "https://foo.bar/baz", sendErr: expectedErr}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	_, err = publisher.PublishBatch(context.Background(), []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})
+
+	assert.Equal(t, expectedErr, err)
+	assert.Len(t, client.batches, 2)
+}
+
+func TestPublisher_PublishPreparedReportsInputIndices(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	inputs := []*sqs.SendMessageInput{}
+	for _, body := range []string{"bar", "foo", "bar"} {
+		input, err := publisher.Prepare(context.Background(), body)
+		require.Nil(t, err)
+		inputs = append(inputs, input)
+	}
+	inputs[2].QueueUrl = aws.String("https://foo.bar/other")
+
+	_, err = publisher.PublishPrepared(context.Background(), inputs)
+
+	require.IsType(t, PartialError{}, err)
+	require.Len(t, err.(PartialError).Errors, 1)
+	assert.Equal(t, "1", aws.ToString(err.(PartialError).Errors[0].Id))
+
+	require.Len(t, client.batches, 2)
+	queues := []string{aws.ToString(client.batches[0].QueueUrl), aws.ToString(client.batches[1].QueueUrl)}
+	assert.ElementsMatch(t, []string{"https://foo.bar/baz", "https://foo.bar/other"}, queues)
+}
+
+func TestPublisher_PublishBatchMapsFailuresToMessages(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	messages := []interface{}{}
+	for i := 0; i < 12; i++ {
+		messages = append(messages, "bar")
+	}
+	messages[3], messages[11] = "foo", "foo"
+
+	_, err = publisher.PublishBatch(context.Background(), messages)
+
+	var partial PartialError
+	require.ErrorAs(t, err, &partial)
+	require.Len(t, partial.Failures, 2)
+	assert.Equal(t, Failure{Index: 3, Message: "foo", Code: "InternalError", Reason: "foo"}, partial.Failures[0])
+	assert.Equal(t, 11, partial.Failures[1].Index)
+	assert.Equal(t, []interface{}{"foo", "foo"}, partial.FailedMessages())
+	assert.True(t, partial.Retryable())
+}
+
+func TestPartialError_Retryable(t *testing.T) {
+	err := PartialError{Failures: []Failure{{Code: "InternalError"}, {Code: "RequestThrottled", SenderFault: true}}}
+	assert.True(t, err.Retryable())
+
+	err.Failures = append(err.Failures, Failure{Code: "InvalidParameterValue", SenderFault: true})
+	assert.False(t, err.Retryable())
+
+	assert.False(t, PartialError{}.Retryable())
+}
+
+func TestPartialError_RequestErrRetryable(t *testing.T) {
+	chunk := []types.SendMessageBatchRequestEntry{{Id: aws.String("0")}}
+	for err, retryable := range map[error]bool{
+		&smithy.GenericAPIError{Code: "AccessDenied", Fault: smithy.FaultClient}:        false,
+		&smithy.GenericAPIError{Code: "ThrottlingException", Fault: smithy.FaultClient}: true,
+		&smithy.GenericAPIError{Code: "InternalError", Fault: smithy.FaultServer}:       true,
+		errors.New("connection reset"):                                                  true,
+	} {
+		result := &batchResult{}
+		result.add(chunk, nil, err)
+
+		assert.Equal(t, retryable, newPartialError(result.total, result.failed, []interface{}{"foo"}).Retryable(), err.Error())
+	}
+}
+
+func TestPartialError_UnknownEntryID(t *testing.T) {
+	failed := []types.BatchResultErrorEntry{{Id: aws.String("foo")}, {}, {Id: aws.String("1")}}
+	err := newPartialError(2, failed, []interface{}{"foo", "bar"})
+
+	require.Len(t, err.Failures, 3)
+	assert.Equal(t, []int{-1, -1, 1}, []int{err.Failures[0].Index, err.Failures[1].Index, err.Failures[2].Index})
+	assert.Nil(t, err.Failures[0].Message)
+	assert.Equal(t, []interface{}{"bar"}, err.FailedMessages())
+}
+
+func TestPartialError_ErrorWithoutMessage(t *testing.T) {
+	err := PartialError{Total: 2, Errors: []types.BatchResultErrorEntry{{Id: aws.String("1"), SenderFault: true}}}
+
+	assert.Equal(t, "1 of 2 messages failed to publish:\n [Code:,EntryId:1,SenderFault:true]", err.Error())
+}
@@ -0,0 +1,380 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+var ErrPublisherClosed = errors.New("publisher is closed")
+
+type BufferConfig struct {
+	// MaxEntries per request, SQS allows at most 10
+	MaxEntries int
+	// MaxBytes of all buffered messages per request
+	MaxBytes int
+	// Linger is the longest time a message waits for further messages before its request is sent
+	Linger time.Duration
+	// BufferSize is the number of messages waiting to be buffered before Publish blocks
+	BufferSize int
+}
+
+func DefaultBufferConfig() BufferConfig {
+	return BufferConfig{
+		MaxEntries: maxEntriesPerRequest,
+		MaxBytes:   MaxMessageSize,
+		Linger:     100 * time.Millisecond,
+		BufferSize: 1000,
+	}
+}
+
+// Future is completed once a message published by BufferedPublisher got sent or failed to
+type Future struct {
+	done   chan struct{}
+	result *Result
+	err    error
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+func (f *Future) complete(result *Result, err error) {
+	f.result = result
+	f.err = err
+	close(f.done)
+}
+
+// Done is closed when the message is completed
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Err blocks till the message is completed and returns its error
+func (f *Future) Err() error {
+	<-f.done
+	return f.err
+}
+
+// Result blocks till the message is completed and returns its result, nil if it failed
+func (f *Future) Result() (*Result, error) {
+	<-f.done
+	return f.result, f.err
+}
+
+// Wait blocks till the message is completed or the context is done
+func (f *Future) Wait(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type bufferedMessage struct {
+	message interface{}
+	entry   types.SendMessageBatchRequestEntry
+	size    int
+	future  *Future
+}
+
+// BufferedPublisher collects messages from many goroutines and sends them in batches
+// once MaxEntries or MaxBytes are reached or the oldest message waited for Linger.
+type BufferedPublisher struct {
+	publisher *Publisher
+	config    BufferConfig
+
+	messages  chan *bufferedMessage
+	flushes   chan chan []*Future
+	closing   chan struct{}
+	done      chan struct{}
+	semaphore chan int
+	inFlight  sync.WaitGroup
+	closed    bool
+	// unflushed are the futures no Flush reported when the publisher got closed
+	unflushed []*Future
+	mx        sync.RWMutex
+
+	// ctx of the sends, it is cancelled if Close gives up waiting for them
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewBufferedPublisher(publisher *Publisher, config BufferConfig) *BufferedPublisher {
+	if config.MaxEntries <= 0 || config.MaxEntries > maxEntriesPerRequest {
+		config.MaxEntries = maxEntriesPerRequest
+	}
+	if config.MaxBytes <= 0 || config.MaxBytes > MaxMessageSize {
+		config.MaxBytes = MaxMessageSize
+	}
+
+	// requests to FIFO queues are sent one after another to keep the order of message groups
+	parallel := parallelRequests
+	if publisher.IsFIFO {
+		parallel = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &BufferedPublisher{
+		ctx:       ctx,
+		cancel:    cancel,
+		publisher: publisher,
+		config:    config,
+		messages:  make(chan *bufferedMessage, config.BufferSize),
+		flushes:   make(chan chan []*Future),
+		closing:   make(chan struct{}),
+		done:      make(chan struct{}),
+		semaphore: make(chan int, parallel),
+	}
+
+	go b.run()
+
+	return b
+}
+
+// Publish parses the message and adds it to the buffer, it blocks while the buffer is full.
+// The returned future is completed once the message got sent.
+func (b *BufferedPublisher) Publish(ctx context.Context, message interface{}, opts ...PublishOption) *Future {
+	future := newFuture()
+
+	entry, err := b.publisher.createSendMessageEntry(ctx, message, opts)
+	if err != nil {
+		future.complete(nil, err)
+		return future
+	}
+
+	b.mx.RLock()
+	defer b.mx.RUnlock()
+
+	if b.closed {
+		future.complete(nil, ErrPublisherClosed)
+		return future
+	}
+
+	buffered := &bufferedMessage{
+		message: message,
+		entry:   *entry,
+		size:    messageSize(aws.ToString(entry.MessageBody), entry.MessageAttributes),
+		future:  future,
+	}
+
+	select {
+	case b.messages <- buffered:
+	case <-ctx.Done():
+		future.complete(nil, ctx.Err())
+	}
+
+	return future
+}
+
+// Flush sends all buffered messages and waits till every message published so far is completed.
+// It returns the joined errors of the messages which failed since the previous Flush, also once closed.
+func (b *BufferedPublisher) Flush(ctx context.Context) error {
+	ack := make(chan []*Future, 1)
+
+	select {
+	case b.flushes <- ack:
+	case <-b.done:
+		b.mx.Lock()
+		futures := b.unflushed
+		b.unflushed = nil
+		b.mx.Unlock()
+
+		return joinFutureErrors(ctx, futures)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	var futures []*Future
+	select {
+	case futures = <-ack:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return joinFutureErrors(ctx, futures)
+}
+
+// joinFutureErrors waits till the futures are completed and joins their errors
+func joinFutureErrors(ctx context.Context, futures []*Future) error {
+	errs := []error{}
+	for _, f := range futures {
+		select {
+		case <-f.Done():
+			errs = append(errs, f.err)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close stops accepting messages, sends the buffered ones and waits till all messages are completed.
+// If the context is done first the sends in flight are cancelled, their messages fail with context.Canceled.
+func (b *BufferedPublisher) Close(ctx context.Context) error {
+	b.mx.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.closing)
+	}
+	b.mx.Unlock()
+
+	select {
+	case <-b.done:
+		b.cancel()
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		<-b.done
+		return ctx.Err()
+	}
+}
+
+func (b *BufferedPublisher) run() {
+	defer close(b.done)
+
+	pending := []*bufferedMessage{}
+	pendingBytes := 0
+	// unflushed are the futures the next Flush reports, succeeded ones are dropped
+	unflushed := []*Future{}
+
+	linger := time.NewTimer(b.config.Linger)
+	linger.Stop()
+
+	dispatch := func() {
+		linger.Stop()
+		if len(pending) == 0 {
+			return
+		}
+
+		unflushed = unsucceeded(unflushed)
+		for _, m := range pending {
+			unflushed = append(unflushed, m.future)
+		}
+
+		b.dispatch(pending)
+		pending = []*bufferedMessage{}
+		pendingBytes = 0
+	}
+
+	add := func(m *bufferedMessage) {
+		if len(pending) > 0 && pendingBytes+m.size > b.config.MaxBytes {
+			dispatch()
+		}
+		if len(pending) == 0 {
+			linger.Reset(b.config.Linger)
+		}
+
+		pending = append(pending, m)
+		pendingBytes += m.size
+
+		if len(pending) >= b.config.MaxEntries {
+			dispatch()
+		}
+	}
+
+	for {
+		select {
+		case m := <-b.messages:
+			add(m)
+		case <-linger.C:
+			dispatch()
+		case ack := <-b.flushes:
+			// messages published before Flush was called may still wait in the channel
+			for len(b.messages) > 0 {
+				add(<-b.messages)
+			}
+			dispatch()
+			ack <- unflushed
+			unflushed = []*Future{}
+		case <-b.closing:
+			// no Publish can add messages anymore once closing is closed
+			for len(b.messages) > 0 {
+				add(<-b.messages)
+			}
+			dispatch()
+
+			b.inFlight.Wait()
+			b.unflushed = unsucceeded(unflushed)
+			return
+		}
+	}
+}
+
+// dispatch sends the messages in the background, blocking while too many requests are in flight.
+// With a single request in flight the requests are sent in the order of dispatch.
+func (b *BufferedPublisher) dispatch(messages []*bufferedMessage) {
+	b.semaphore <- 1
+	b.inFlight.Add(1)
+
+	go func() {
+		defer b.inFlight.Done()
+		defer func() { <-b.semaphore }()
+
+		entries := []types.SendMessageBatchRequestEntry{}
+		for i, m := range messages {
+			entry := m.entry
+			entry.Id = aws.String(strconv.Itoa(i))
+			entries = append(entries, entry)
+		}
+
+		queueURL, err := b.publisher.queueURL(b.ctx)
+		if err != nil {
+			for _, m := range messages {
+				m.future.complete(nil, err)
+			}
+			return
+		}
+
+		result := b.publisher.send(b.ctx, queueURL, entries)
+
+		failed := map[string]types.BatchResultErrorEntry{}
+		for _, f := range result.failed {
+			failed[aws.ToString(f.Id)] = f
+		}
+		successful := map[int]Result{}
+		for _, r := range result.successful {
+			successful[r.Index] = r
+		}
+
+		for i, m := range messages {
+			f, ok := failed[strconv.Itoa(i)]
+			switch {
+			case !ok:
+				// the index within the request means nothing to the caller
+				r := successful[i]
+				r.Index = 0
+				m.future.complete(&r, nil)
+			case aws.ToString(f.Code) == requestFailedCode && result.requestErr != nil:
+				m.future.complete(nil, result.requestErr)
+			default:
+				f.Id = aws.String("0")
+				m.future.complete(nil, newPartialError(1, []types.BatchResultErrorEntry{f}, []interface{}{m.message}))
+			}
+		}
+	}()
+}
+
+// unsucceeded returns the futures which are not completed or failed
+func unsucceeded(futures []*Future) []*Future {
+	result := []*Future{}
+	for _, f := range futures {
+		select {
+		case <-f.Done():
+			if f.err != nil {
+				result = append(result, f)
+			}
+		default:
+			result = append(result, f)
+		}
+	}
+
+	return result
+}
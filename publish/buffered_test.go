@@ -0,0 +1,175 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBufferedPublisher(t *testing.T, client *MockClient, linger time.Duration) *BufferedPublisher {
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	config := DefaultBufferConfig()
+	config.Linger = linger
+
+	return NewBufferedPublisher(publisher, config)
+}
+
+func TestBufferedPublisher_PublishConcurrently(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	buffered := newBufferedPublisher(t, client, time.Hour)
+
+	wg := &sync.WaitGroup{}
+	futures := make([]*Future, 25)
+	for i := range futures {
+		wg.Add(1)
+		go func(i int) {
+			futures[i] = buffered.Publish(context.Background(), i)
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+
+	require.Nil(t, buffered.Close(context.Background()))
+
+	for _, f := range futures {
+		assert.Nil(t, f.Err())
+	}
+	require.Len(t, client.batches, 3)
+	for _, batch := range client.batches {
+		assert.LessOrEqual(t, len(batch.Entries), 10)
+	}
+
+	assert.ErrorIs(t, buffered.Publish(context.Background(), "foo").Err(), ErrPublisherClosed)
+}
+
+func TestBufferedPublisher_DispatchesFIFOSerially(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz.fifo", batchDelay: 10 * time.Millisecond}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz.fifo", IsFIFO: true}, client)
+	require.Nil(t, err)
+	config := DefaultBufferConfig()
+	config.MaxEntries = 2
+	buffered := NewBufferedPublisher(publisher, config)
+
+	for i := 0; i < 10; i++ {
+		buffered.Publish(context.Background(), i, WithMessageGroupID("foo"))
+	}
+	require.Nil(t, buffered.Close(context.Background()))
+
+	assert.Equal(t, int32(1), client.maxInFlight)
+	require.Len(t, client.batches, 5)
+	for i, batch := range client.batches {
+		assert.Equal(t, strconv.Itoa(2*i), aws.ToString(batch.Entries[0].MessageBody))
+	}
+}
+
+func TestBufferedPublisher_Linger(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	buffered := newBufferedPublisher(t, client, 10*time.Millisecond)
+	defer buffered.Close(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.Nil(t, buffered.Publish(ctx, "foo").Wait(ctx))
+	assert.Len(t, client.batches, 1)
+}
+
+func TestBufferedPublisher_Flush(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	buffered := newBufferedPublisher(t, client, time.Hour)
+	defer buffered.Close(context.Background())
+
+	first := buffered.Publish(context.Background(), "foo")
+	second := buffered.Publish(context.Background(), "bar")
+	require.Nil(t, buffered.Flush(context.Background()))
+
+	assert.Nil(t, first.Err())
+	assert.Nil(t, second.Err())
+	require.Len(t, client.batches, 1)
+	assert.Len(t, client.batches[0].Entries, 2)
+}
+
+func TestBufferedPublisher_Failures(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo"}
+	buffered := newBufferedPublisher(t, client, time.Hour)
+
+	failed := buffered.Publish(context.Background(), "foo")
+	succeeded := buffered.Publish(context.Background(), "bar")
+	require.Nil(t, buffered.Close(context.Background()))
+
+	assert.IsType(t, PartialError{}, failed.Err())
+	assert.Nil(t, succeeded.Err())
+
+	expectedErr := errors.New("foo bar baz")
+	client = &MockClient{queueUrl: "https://foo.bar/baz", sendErr: expectedErr}
+	buffered = newBufferedPublisher(t, client, time.Hour)
+
+	future := buffered.Publish(context.Background(), "foo")
+	require.Nil(t, buffered.Close(context.Background()))
+
+	assert.Equal(t, expectedErr, future.Err())
+}
+
+func TestBufferedPublisher_FlushReturnsFailures(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo", senderFault: true}
+	buffered := newBufferedPublisher(t, client, time.Hour)
+	defer buffered.Close(context.Background())
+
+	failed := buffered.Publish(context.Background(), "foo")
+	buffered.Publish(context.Background(), "bar")
+	err := buffered.Flush(context.Background())
+	require.Error(t, failed.Err())
+	assert.ErrorAs(t, err, &PartialError{})
+	assert.EqualError(t, err, failed.Err().Error())
+
+	buffered.Publish(context.Background(), "bar")
+	assert.Nil(t, buffered.Flush(context.Background()))
+}
+
+func TestBufferedPublisher_FlushAfterClose(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo", senderFault: true}
+	buffered := newBufferedPublisher(t, client, time.Hour)
+
+	failed := buffered.Publish(context.Background(), "foo")
+	buffered.Publish(context.Background(), "bar")
+	require.Nil(t, buffered.Close(context.Background()))
+
+	err := buffered.Flush(context.Background())
+	require.Error(t, failed.Err())
+	assert.EqualError(t, err, failed.Err().Error())
+	assert.Nil(t, buffered.Flush(context.Background()))
+}
+
+func TestBufferedPublisher_CloseCancelsSends(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", blockBatches: true}
+	buffered := newBufferedPublisher(t, client, time.Hour)
+
+	future := buffered.Publish(context.Background(), "foo")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, buffered.Close(ctx), context.DeadlineExceeded)
+	assert.ErrorIs(t, future.Err(), context.Canceled)
+}
+
+func TestBufferedPublisher_FutureResult(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	buffered := newBufferedPublisher(t, client, time.Hour)
+
+	future := buffered.Publish(context.Background(), "foo")
+	require.Nil(t, buffered.Flush(context.Background()))
+
+	result, err := future.Result()
+	require.Nil(t, err)
+	assert.Equal(t, "message-0", result.MessageID)
+	assert.Equal(t, 0, result.Index)
+}
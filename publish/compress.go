@@ -0,0 +1,34 @@
+package publish
+
+import "git.limango.tech/shop-catalog/libraries/sqs-queue.git/compression"
+
+const DefaultCompressionThreshold = 1024
+
+// Compressor compresses message bodies larger than Threshold bytes and marks them with a Content-Encoding attribute
+type Compressor struct {
+	Codec     compression.Codec
+	Threshold int
+}
+
+func NewCompressor(codec compression.Codec) *Compressor {
+	return &Compressor{
+		Codec:     codec,
+		Threshold: DefaultCompressionThreshold,
+	}
+}
+
+func (c *Compressor) compress(message *preparedMessage) error {
+	if len(message.body) <= c.Threshold {
+		return nil
+	}
+
+	body, err := compression.Encode(c.Codec, message.body)
+	if err != nil {
+		return err
+	}
+
+	message.body = body
+	message.attributes[compression.EncodingAttribute] = StringAttribute(c.Codec.Name())
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package publish
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/compression"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisher_PublishCompressesAboveThreshold(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+	publisher.Compressor = NewCompressor(compression.Gzip)
+
+	large := strings.Repeat("foo bar baz ", 200)
+	_, err = publisher.PublishBatch(context.Background(), []interface{}{"foo", large})
+	require.Nil(t, err)
+
+	require.Len(t, client.batches, 1)
+	small, compressed := client.batches[0].Entries[0], client.batches[0].Entries[1]
+
+	assert.NotContains(t, small.MessageAttributes, compression.EncodingAttribute)
+	assert.Equal(t, "foo", aws.ToString(small.MessageBody))
+
+	require.Contains(t, compressed.MessageAttributes, compression.EncodingAttribute)
+	assert.Equal(t, "gzip", aws.ToString(compressed.MessageAttributes[compression.EncodingAttribute].StringValue))
+	body, err := compression.Decode(compression.Gzip, aws.ToString(compressed.MessageBody))
+	require.Nil(t, err)
+	assert.Equal(t, large, body)
+}
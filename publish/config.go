@@ -1,6 +1,37 @@
 package publish
 
+import (
+	"strings"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+)
+
 type PublisherConfig struct {
+	// QueueName is the name, URL or ARN of the queue
 	QueueName string `envconfig:"AWS_SQS_QUEUE_NAME" required:"true"`
 	IsFIFO    bool   `envconfig:"AWS_SQS_FIFO_QUEUE"`
+	// QueueOwnerAWSAccountID is the account of a queue of another account given by name
+	QueueOwnerAWSAccountID string `envconfig:"AWS_SQS_QUEUE_OWNER_ACCOUNT_ID"`
+	// LazyQueueURL resolves the queue URL on the first publish instead of in NewPublisher, retrying failures
+	LazyQueueURL bool `envconfig:"AWS_SQS_LAZY_QUEUE_URL"`
+}
+
+// Validate reports a missing queue name and an IsFIFO contradicting the .fifo suffix of the queue name
+func (c PublisherConfig) Validate() error {
+	if c.QueueName == "" {
+		return utils.InvalidConfig("QueueName is required")
+	}
+	if c.IsFIFO != strings.HasSuffix(c.QueueName, ".fifo") {
+		return utils.InvalidConfig("IsFIFO has to be set exactly for queues named *.fifo")
+	}
+
+	return nil
+}
+
+// LoadPublisherConfig loads the config from the environment, see utils.LoadConfig
+func LoadPublisherConfig(prefix string) (PublisherConfig, error) {
+	config := PublisherConfig{}
+	err := utils.LoadConfig(prefix, &config)
+
+	return config, err
 }
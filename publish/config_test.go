@@ -0,0 +1,41 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPublisherConfig(t *testing.T) {
+	t.Setenv("AWS_SQS_QUEUE_NAME", "orders.fifo")
+	t.Setenv("AWS_SQS_FIFO_QUEUE", "true")
+
+	config, err := LoadPublisherConfig("")
+	require.Nil(t, err)
+	assert.Equal(t, PublisherConfig{QueueName: "orders.fifo", IsFIFO: true}, config)
+
+	t.Setenv("AWS_SQS_FIFO_QUEUE", "false")
+	_, err = LoadPublisherConfig("")
+	assert.ErrorIs(t, err, utils.ErrInvalidConfig)
+}
+
+func TestNewPublisher_LazyQueueURL(t *testing.T) {
+	client := &MockClient{queueUrlErr: errors.New("unavailable")}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz", LazyQueueURL: true}, client)
+	require.Nil(t, err)
+
+	_, err = publisher.Publish(context.Background(), "foo")
+	assert.EqualError(t, err, "unavailable")
+	assert.Empty(t, client.sent)
+
+	client.queueUrl, client.queueUrlErr = "https://foo.bar/baz", nil
+	_, err = publisher.Publish(context.Background(), "foo")
+	require.Nil(t, err)
+	require.Len(t, client.sent, 1)
+	assert.Equal(t, "https://foo.bar/baz", aws.ToString(client.sent[0].QueueUrl))
+}
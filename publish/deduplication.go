@@ -0,0 +1,81 @@
+package publish
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+)
+
+// maxDeduplicationIDLength is the SQS limit for MessageDeduplicationId
+const maxDeduplicationIDLength = 128
+
+// DeduplicationStrategy creates the deduplication id of messages for FIFO queues.
+// SQS drops a message if a message with the same deduplication id got sent to the queue within the last 5 minutes,
+// this window is fixed by AWS and is not extended by duplicates. Outside the window the same id is accepted again.
+// Besides a strategy the id can be set per call with WithDeduplicationID.
+type DeduplicationStrategy interface {
+	DeduplicationID(message interface{}, params MessageParams) (string, error)
+}
+
+// RandomDeduplication creates a unique id per message, effectively disabling deduplication.
+// A message sent twice, e.g. by a retry after a timeout, is delivered twice.
+type RandomDeduplication struct{}
+
+func (RandomDeduplication) DeduplicationID(interface{}, MessageParams) (string, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// ContentDeduplication uses the SHA-256 of message type and body.
+// Identical messages within 5 minutes are delivered once, even if they were sent twice on purpose.
+type ContentDeduplication struct{}
+
+func (ContentDeduplication) DeduplicationID(_ interface{}, params MessageParams) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(params.MessageType))
+	hash.Write([]byte{0})
+	hash.Write([]byte(params.Body))
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// FieldDeduplication uses the value of a field of the message, matched by Go name or json name for structs and by key for maps.
+// Messages with the same value within 5 minutes are delivered once, e.g. the id of an event sent again on retry.
+type FieldDeduplication struct {
+	Field string
+}
+
+func (f FieldDeduplication) DeduplicationID(message interface{}, _ MessageParams) (string, error) {
+	value, ok := extractField(message, f.Field)
+	if !ok || value == "" {
+		return "", fmt.Errorf("deduplication field '%s' not found in message of type %T", f.Field, message)
+	}
+
+	return checkDeduplicationID(value)
+}
+
+// DeduplicationFunc extracts the deduplication id from the message with own logic, the 5 minutes window applies per returned id
+type DeduplicationFunc func(message interface{}) (string, error)
+
+func (f DeduplicationFunc) DeduplicationID(message interface{}, _ MessageParams) (string, error) {
+	value, err := f(message)
+	if err != nil {
+		return "", err
+	}
+
+	return checkDeduplicationID(value)
+}
+
+func checkDeduplicationID(id string) (string, error) {
+	if len(id) > maxDeduplicationIDLength {
+		return "", fmt.Errorf("deduplication id exceeds %d characters", maxDeduplicationIDLength)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,75 @@
+package publish
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderEvent struct {
+	EventID string `json:"event_id"`
+	Order   int
+}
+
+func TestRandomDeduplication(t *testing.T) {
+	first, err := RandomDeduplication{}.DeduplicationID("foo", MessageParams{})
+	require.Nil(t, err)
+	second, err := RandomDeduplication{}.DeduplicationID("foo", MessageParams{})
+	require.Nil(t, err)
+
+	assert.NotEqual(t, first, second)
+}
+
+func TestContentDeduplication(t *testing.T) {
+	strategy := ContentDeduplication{}
+
+	first, err := strategy.DeduplicationID(nil, MessageParams{MessageType: "foo", Body: "bar"})
+	require.Nil(t, err)
+	same, err := strategy.DeduplicationID(nil, MessageParams{MessageType: "foo", Body: "bar"})
+	require.Nil(t, err)
+	otherType, err := strategy.DeduplicationID(nil, MessageParams{MessageType: "baz", Body: "bar"})
+	require.Nil(t, err)
+
+	assert.Equal(t, first, same)
+	assert.NotEqual(t, first, otherType)
+	assert.Len(t, first, 64)
+}
+
+func TestFieldDeduplication(t *testing.T) {
+	event := orderEvent{EventID: "foo", Order: 42}
+
+	id, err := FieldDeduplication{Field: "event_id"}.DeduplicationID(&event, MessageParams{})
+	require.Nil(t, err)
+	assert.Equal(t, "foo", id)
+
+	id, err = FieldDeduplication{Field: "Order"}.DeduplicationID(event, MessageParams{})
+	require.Nil(t, err)
+	assert.Equal(t, "42", id)
+
+	id, err = FieldDeduplication{Field: "id"}.DeduplicationID(map[string]interface{}{"id": "bar"}, MessageParams{})
+	require.Nil(t, err)
+	assert.Equal(t, "bar", id)
+
+	_, err = FieldDeduplication{Field: "missing"}.DeduplicationID(event, MessageParams{})
+	assert.Error(t, err)
+
+	_, err = FieldDeduplication{Field: "id"}.DeduplicationID(map[string]string{"id": strings.Repeat("x", 129)}, MessageParams{})
+	assert.Error(t, err)
+}
+
+func TestDefaultMessageParser_Deduplication(t *testing.T) {
+	parser := NewDefaultMessageParser()
+	parser.Deduplication = DeduplicationFunc(func(message interface{}) (string, error) {
+		return "order-" + message.(orderEvent).EventID, nil
+	})
+
+	params, err := parser.Parse(orderEvent{EventID: "foo"})
+	require.Nil(t, err)
+	assert.Equal(t, "order-foo", params.DeduplicationID)
+
+	params, err = DefaultMessageParser{}.Parse("foo")
+	require.Nil(t, err)
+	assert.NotEmpty(t, params.DeduplicationID)
+}
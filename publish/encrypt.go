@@ -0,0 +1,37 @@
+package publish
+
+import (
+	"context"
+	"encoding/base64"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/encryption"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Encrypter encrypts message bodies with a data key from Provider, algorithm, key id and wrapped data key are sent as attributes.
+// The key id and the message type are authenticated with the body.
+type Encrypter struct {
+	Provider encryption.KeyProvider
+}
+
+func NewEncrypter(provider encryption.KeyProvider) *Encrypter {
+	return &Encrypter{
+		Provider: provider,
+	}
+}
+
+func (e *Encrypter) encrypt(ctx context.Context, message *preparedMessage) error {
+	messageType := aws.ToString(message.attributes[utils.MessageTypeAttribute].StringValue)
+	envelope, err := encryption.Seal(ctx, e.Provider, []byte(message.body), []byte(messageType))
+	if err != nil {
+		return err
+	}
+
+	message.body = base64.StdEncoding.EncodeToString(envelope.Ciphertext)
+	message.attributes[encryption.AlgorithmAttribute] = StringAttribute(envelope.Algorithm)
+	message.attributes[encryption.KeyIDAttribute] = StringAttribute(envelope.KeyID)
+	message.attributes[encryption.DataKeyAttribute] = BinaryAttribute(envelope.EncryptedKey)
+
+	return nil
+}
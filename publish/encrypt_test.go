@@ -0,0 +1,45 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/encryption"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisher_PublishEncrypts(t *testing.T) {
+	ctx := context.Background()
+	provider, err := encryption.NewStaticKeyProvider("foo", map[string][]byte{"foo": bytes.Repeat([]byte{1}, 32)})
+	require.Nil(t, err)
+
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+	publisher.Encrypter = NewEncrypter(provider)
+
+	_, err = publisher.Publish(ctx, map[string]string{"foo": "bar"})
+	require.Nil(t, err)
+
+	require.Len(t, client.sent, 1)
+	attributes := client.sent[0].MessageAttributes
+	assert.Equal(t, encryption.AlgorithmAESGCM, aws.ToString(attributes[encryption.AlgorithmAttribute].StringValue))
+	assert.Equal(t, "foo", aws.ToString(attributes[encryption.KeyIDAttribute].StringValue))
+
+	ciphertext, err := base64.StdEncoding.DecodeString(aws.ToString(client.sent[0].MessageBody))
+	require.Nil(t, err)
+	plaintext, err := encryption.Open(ctx, provider, encryption.Envelope{
+		Algorithm:      encryption.AlgorithmAESGCM,
+		KeyID:          "foo",
+		EncryptedKey:   attributes[encryption.DataKeyAttribute].BinaryValue,
+		Ciphertext:     ciphertext,
+		AssociatedData: []byte(aws.ToString(attributes[utils.MessageTypeAttribute].StringValue)),
+	})
+	require.Nil(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, string(plaintext))
+}
@@ -0,0 +1,91 @@
+package publish
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// tagName is the struct tag marking fields with a special meaning for publishing, e.g. `sqs:"group"`
+const tagName = "sqs"
+
+// extractField returns the value of a struct field or map key of the message as string.
+// Struct fields match by Go name or json tag name.
+func extractField(message interface{}, name string) (string, bool) {
+	value := reflect.ValueOf(message)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", false
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return "", false
+		}
+		field := value.MapIndex(reflect.ValueOf(name).Convert(value.Type().Key()))
+		if !field.IsValid() {
+			return "", false
+		}
+		return stringify(field)
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if field.Name == name || jsonName == name {
+				return stringify(value.Field(i))
+			}
+		}
+	}
+
+	return "", false
+}
+
+// extractTagged returns the value of the struct field tagged with `sqs:"<tag>"` as string
+func extractTagged(message interface{}, tag string) (string, bool) {
+	value := reflect.ValueOf(message)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", false
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.IsExported() && field.Tag.Get(tagName) == tag {
+			return stringify(value.Field(i))
+		}
+	}
+
+	return "", false
+}
+
+func stringify(value reflect.Value) (string, bool) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", false
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Func, reflect.Chan:
+		if stringer, ok := value.Interface().(fmt.Stringer); ok {
+			return stringer.String(), true
+		}
+		return "", false
+	}
+
+	return fmt.Sprint(value.Interface()), true
+}
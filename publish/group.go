@@ -0,0 +1,32 @@
+package publish
+
+// groupTag marks the struct field holding the message group id, e.g. `sqs:"group"`
+const groupTag = "group"
+
+// GroupKeyer can be implemented by messages to provide their FIFO message group id
+type GroupKeyer interface {
+	GroupKey() string
+}
+
+// GroupKeyFunc extracts the FIFO message group id from a message, returning an empty id if it does not apply
+type GroupKeyFunc func(message interface{}) string
+
+// groupKey resolves the message group id from, in this order, the GroupKeyer interface, the group struct tag and the extractor.
+// An empty id is returned if none of them provides one.
+func groupKey(message interface{}, extractor GroupKeyFunc) string {
+	if keyer, ok := message.(GroupKeyer); ok {
+		if key := keyer.GroupKey(); key != "" {
+			return key
+		}
+	}
+
+	if key, ok := extractTagged(message, groupTag); ok && key != "" {
+		return key
+	}
+
+	if extractor != nil {
+		return extractor(message)
+	}
+
+	return ""
+}
@@ -0,0 +1,66 @@
+package publish
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantEvent struct {
+	Tenant string
+}
+
+func (e tenantEvent) GroupKey() string {
+	return "tenant-" + e.Tenant
+}
+
+type productEvent struct {
+	SKU string `json:"sku" sqs:"group"`
+}
+
+func TestDefaultMessageParser_GroupKey(t *testing.T) {
+	parser := NewDefaultMessageParser()
+	parser.GroupKey = func(message interface{}) string {
+		if m, ok := message.(map[string]string); ok {
+			return m["shop"]
+		}
+		return ""
+	}
+
+	params, err := parser.Parse(tenantEvent{Tenant: "de"})
+	require.Nil(t, err)
+	assert.Equal(t, "tenant-de", params.MessageGroupID)
+
+	params, err = parser.Parse(&productEvent{SKU: "foo"})
+	require.Nil(t, err)
+	assert.Equal(t, "foo", params.MessageGroupID)
+
+	params, err = parser.Parse(map[string]string{"shop": "bar"})
+	require.Nil(t, err)
+	assert.Equal(t, "bar", params.MessageGroupID)
+
+	params, err = parser.Parse("baz")
+	require.Nil(t, err)
+	assert.Empty(t, params.MessageGroupID)
+
+	parser.DefaultMessageGroupID = "default"
+	params, err = parser.Parse("baz")
+	require.Nil(t, err)
+	assert.Equal(t, "default", params.MessageGroupID)
+}
+
+func TestPublisher_PublishRequiresGroupForFIFO(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz", IsFIFO: true}, client)
+	require.Nil(t, err)
+
+	_, err = publisher.Publish(context.Background(), "foo")
+	assert.ErrorIs(t, err, ErrMissingMessageGroup)
+	_, err = publisher.Publish(context.Background(), productEvent{SKU: "foo"})
+	assert.Nil(t, err)
+	_, err = publisher.Publish(context.Background(), "foo", WithMessageGroupID("bar"))
+	assert.Nil(t, err)
+	assert.Len(t, client.sent, 2)
+}
@@ -0,0 +1,108 @@
+package publish
+
+import (
+	"context"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+type MockClient struct {
+	queueUrl    string
+	queueUrlErr error
+	sent        []*sqs.SendMessageInput
+	batches     []*sqs.SendMessageBatchInput
+	failBody    string
+	failTimes   int
+	failCode    string
+	senderFault bool
+	failures    int
+	sendErr     error
+	// checksums makes the mock report MD5 digests, corrupt ones if badChecksum is set
+	checksums   bool
+	badChecksum bool
+	// blockBatches makes SendMessageBatch wait till its context is done
+	blockBatches bool
+	// batchDelay is waited by SendMessageBatch, maxInFlight records the most concurrent calls
+	batchDelay  time.Duration
+	inFlight    int32
+	maxInFlight int32
+	mx          sync.Mutex
+}
+
+func (m *MockClient) SendMessage(_ context.Context, input *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.sent = append(m.sent, input)
+	if m.sendErr != nil {
+		return nil, m.sendErr
+	}
+
+	output := &sqs.SendMessageOutput{MessageId: aws.String("foo"), SequenceNumber: aws.String("1")}
+	output.MD5OfMessageBody, output.MD5OfMessageAttributes = m.digests(input.MessageBody, input.MessageAttributes)
+
+	return output, nil
+}
+
+func (m *MockClient) SendMessageBatch(ctx context.Context, input *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	if m.blockBatches {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	if m.batchDelay > 0 {
+		inFlight := atomic.AddInt32(&m.inFlight, 1)
+		defer atomic.AddInt32(&m.inFlight, -1)
+		for max := atomic.LoadInt32(&m.maxInFlight); inFlight > max && !atomic.CompareAndSwapInt32(&m.maxInFlight, max, inFlight); {
+			max = atomic.LoadInt32(&m.maxInFlight)
+		}
+		time.Sleep(m.batchDelay)
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.batches = append(m.batches, input)
+	if m.sendErr != nil {
+		return nil, m.sendErr
+	}
+
+	output := &sqs.SendMessageBatchOutput{}
+	for _, entry := range input.Entries {
+		if m.failBody != "" && aws.ToString(entry.MessageBody) == m.failBody && (m.failTimes == 0 || m.failures < m.failTimes) {
+			m.failures++
+			code := m.failCode
+			if code == "" {
+				code = "InternalError"
+			}
+			output.Failed = append(output.Failed, types.BatchResultErrorEntry{Id: entry.Id, Code: aws.String(code), Message: aws.String("foo"), SenderFault: m.senderFault})
+			continue
+		}
+		result := types.SendMessageBatchResultEntry{Id: entry.Id, MessageId: aws.String("message-" + aws.ToString(entry.Id))}
+		result.MD5OfMessageBody, result.MD5OfMessageAttributes = m.digests(entry.MessageBody, entry.MessageAttributes)
+		output.Successful = append(output.Successful, result)
+	}
+
+	return output, nil
+}
+
+func (m *MockClient) digests(body *string, attributes map[string]types.MessageAttributeValue) (*string, *string) {
+	if !m.checksums {
+		return nil, nil
+	}
+	if m.badChecksum {
+		return aws.String(utils.BodyMD5("corrupt")), aws.String(utils.AttributesMD5(attributes))
+	}
+
+	return aws.String(utils.BodyMD5(aws.ToString(body))), aws.String(utils.AttributesMD5(attributes))
+}
+
+func (m *MockClient) GetQueueUrl(context.Context, *sqs.GetQueueUrlInput, ...func(o *sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+	return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(m.queueUrl)}, m.queueUrlErr
+}
@@ -0,0 +1,58 @@
+package publish
+
+import (
+	"context"
+	"strconv"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/blob"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gofrs/uuid"
+)
+
+// MaxMessageSize is the SQS limit for body and attributes of a single message
+const MaxMessageSize = 256 * 1024
+
+// Offloader moves messages exceeding Threshold into a blob store, the message then only carries a pointer to the payload
+type Offloader struct {
+	Store     blob.Store
+	Threshold int
+}
+
+func NewOffloader(store blob.Store) *Offloader {
+	return &Offloader{
+		Store:     store,
+		Threshold: MaxMessageSize,
+	}
+}
+
+func (o *Offloader) offload(ctx context.Context, message *preparedMessage) error {
+	if messageSize(message.body, message.attributes) <= o.Threshold {
+		return nil
+	}
+
+	key, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	if err = o.Store.Put(ctx, key.String(), []byte(message.body)); err != nil {
+		return err
+	}
+
+	message.attributes[blob.PointerAttribute] = StringAttribute(key.String())
+	message.attributes[blob.SizeAttribute] = NumberAttribute(strconv.Itoa(len(message.body)))
+	message.body = key.String()
+
+	return nil
+}
+
+// messageSize calculates the size as counted by SQS against MaxMessageSize
+func messageSize(body string, attributes map[string]types.MessageAttributeValue) int {
+	size := len(body)
+	for name, attr := range attributes {
+		size += len(name) + len(aws.ToString(attr.DataType)) + len(aws.ToString(attr.StringValue)) + len(attr.BinaryValue)
+	}
+
+	return size
+}
@@ -0,0 +1,55 @@
+package publish
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/blob"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisher_PublishOffloadsLargeMessage(t *testing.T) {
+	store, err := blob.NewFileStore(t.TempDir())
+	require.Nil(t, err)
+
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+	publisher.Offloader = NewOffloader(store)
+
+	large := strings.Repeat("x", MaxMessageSize)
+	_, err = publisher.Publish(context.Background(), large)
+	require.Nil(t, err)
+
+	require.Len(t, client.sent, 1)
+	input := client.sent[0]
+	require.Contains(t, input.MessageAttributes, blob.PointerAttribute)
+	key := aws.ToString(input.MessageAttributes[blob.PointerAttribute].StringValue)
+	assert.Equal(t, key, aws.ToString(input.MessageBody))
+
+	payload, err := store.Get(context.Background(), key)
+	require.Nil(t, err)
+	assert.Equal(t, large, string(payload))
+}
+
+func TestPublisher_PublishBatchKeepsSmallMessages(t *testing.T) {
+	store, err := blob.NewFileStore(t.TempDir())
+	require.Nil(t, err)
+
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+	publisher.Offloader = NewOffloader(store)
+
+	_, err = publisher.PublishBatch(context.Background(), []interface{}{"foo", "bar"})
+	require.Nil(t, err)
+
+	require.Len(t, client.batches, 1)
+	for _, entry := range client.batches[0].Entries {
+		assert.NotContains(t, entry.MessageAttributes, blob.PointerAttribute)
+	}
+	assert.Equal(t, "foo", aws.ToString(client.batches[0].Entries[0].MessageBody))
+}
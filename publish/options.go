@@ -0,0 +1,114 @@
+package publish
+
+import (
+	"errors"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/codec"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// ErrSharedDeduplicationID is returned for batches of several messages published WithDeduplicationID, SQS would drop
+// all but the first of them as duplicates
+var ErrSharedDeduplicationID = errors.New("a deduplication id cannot be shared by several messages")
+
+// PublishOption overrides the parameters returned by the parser for a single Publish or PublishBatch call
+type PublishOption func(params *MessageParams)
+
+func WithMessageType(messageType string) PublishOption {
+	return func(params *MessageParams) {
+		params.MessageType = messageType
+	}
+}
+
+func WithContentType(contentType string) PublishOption {
+	return func(params *MessageParams) {
+		params.ContentType = contentType
+	}
+}
+
+// WithCodec encodes the message with the codec instead of the parser's one, the parser has to implement CodecParser
+func WithCodec(c codec.Codec) PublishOption {
+	return func(params *MessageParams) {
+		params.Codec = c
+	}
+}
+
+func WithMessageGroupID(groupID string) PublishOption {
+	return func(params *MessageParams) {
+		params.MessageGroupID = groupID
+	}
+}
+
+// WithDeduplicationID sets the deduplication id of a single message, batches of several messages are rejected with
+// ErrSharedDeduplicationID, a DeduplicationStrategy derives ids per message instead
+func WithDeduplicationID(deduplicationID string) PublishOption {
+	return func(params *MessageParams) {
+		params.DeduplicationID = deduplicationID
+	}
+}
+
+func WithDelaySeconds(seconds int32) PublishOption {
+	return func(params *MessageParams) {
+		params.DelaySeconds = seconds
+	}
+}
+
+// WithDeliverAt schedules the message for the time, consumers with a queue.Scheduler do not handle it before
+func WithDeliverAt(at time.Time) PublishOption {
+	return func(params *MessageParams) {
+		params.DeliverAt = at
+	}
+}
+
+// WithDeliverAfter schedules the message for the duration after the option got created
+func WithDeliverAfter(d time.Duration) PublishOption {
+	return WithDeliverAt(time.Now().Add(d))
+}
+
+func WithAttribute(name string, value types.MessageAttributeValue) PublishOption {
+	return func(params *MessageParams) {
+		if params.Attributes == nil {
+			params.Attributes = map[string]types.MessageAttributeValue{}
+		}
+		params.Attributes[name] = value
+	}
+}
+
+func WithStringAttribute(name string, value string) PublishOption {
+	return WithAttribute(name, StringAttribute(value))
+}
+
+func WithNumberAttribute(name string, value string) PublishOption {
+	return WithAttribute(name, NumberAttribute(value))
+}
+
+func WithBinaryAttribute(name string, value []byte) PublishOption {
+	return WithAttribute(name, BinaryAttribute(value))
+}
+
+func WithSystemAttribute(name string, value types.MessageSystemAttributeValue) PublishOption {
+	return func(params *MessageParams) {
+		if params.SystemAttributes == nil {
+			params.SystemAttributes = map[string]types.MessageSystemAttributeValue{}
+		}
+		params.SystemAttributes[name] = value
+	}
+}
+
+// checkBatchOptions rejects options which cannot apply to every message of a batch
+func checkBatchOptions(messages []interface{}, opts []PublishOption) error {
+	if len(messages) < 2 {
+		return nil
+	}
+
+	params := MessageParams{}
+	for _, opt := range opts {
+		opt(&params)
+	}
+	if params.DeduplicationID != "" {
+		return ErrSharedDeduplicationID
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package publish
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantMessage struct {
+	Tenant string `json:"tenant"`
+}
+
+func (m tenantMessage) MessageAttributes() map[string]types.MessageAttributeValue {
+	return map[string]types.MessageAttributeValue{"Tenant": StringAttribute(m.Tenant)}
+}
+
+func TestPublisher_PublishWithOptions(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	trace := types.MessageSystemAttributeValue{DataType: aws.String("String"), StringValue: aws.String("Root=1-foo")}
+	message := tenantMessage{Tenant: "foo"}
+	_, err = publisher.Publish(context.Background(), message,
+		WithStringAttribute("Locale", "de_DE"),
+		WithNumberAttribute("Retry", "3"),
+		WithBinaryAttribute("Checksum", []byte{1, 2}),
+		WithStringAttribute("Message-Type", "ignored"),
+		WithMessageType("product"),
+		WithDelaySeconds(30),
+		WithSystemAttribute("AWSTraceHeader", trace),
+	)
+	require.Nil(t, err)
+
+	require.Len(t, client.sent, 1)
+	input := client.sent[0]
+	assert.Equal(t, int32(30), input.DelaySeconds)
+	assert.Equal(t, trace, input.MessageSystemAttributes["AWSTraceHeader"])
+	assert.Equal(t, map[string]types.MessageAttributeValue{
+		"Tenant":       StringAttribute("foo"),
+		"Locale":       StringAttribute("de_DE"),
+		"Retry":        NumberAttribute("3"),
+		"Checksum":     BinaryAttribute([]byte{1, 2}),
+		"Message-Type": StringAttribute("product"),
+		"Content-Type": StringAttribute("application/json"),
+	}, input.MessageAttributes)
+}
+
+func TestPublisher_PublishBatchWithOptions(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz", IsFIFO: true}, client)
+	require.Nil(t, err)
+
+	_, err = publisher.PublishBatch(context.Background(), []interface{}{"foo", "bar"}, WithMessageGroupID("tenant-1"), WithStringAttribute("Tenant", "1"))
+	require.Nil(t, err)
+
+	require.Len(t, client.batches, 1)
+	for _, entry := range client.batches[0].Entries {
+		assert.Equal(t, "tenant-1", aws.ToString(entry.MessageGroupId))
+		assert.Equal(t, StringAttribute("1"), entry.MessageAttributes["Tenant"])
+	}
+}
+
+func TestPublisher_PublishBatchRejectsSharedDeduplicationID(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz.fifo"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz.fifo", IsFIFO: true}, client)
+	require.Nil(t, err)
+
+	_, err = publisher.PublishBatch(context.Background(), []interface{}{"foo", "bar"}, WithMessageGroupID("tenant-1"), WithDeduplicationID("foo"))
+	assert.ErrorIs(t, err, ErrSharedDeduplicationID)
+	assert.Empty(t, client.batches)
+
+	router := NewRoutingPublisher()
+	router.Fallback = publisher
+	_, err = router.PublishBatch(context.Background(), []interface{}{"foo", "bar"}, WithMessageGroupID("tenant-1"), WithDeduplicationID("foo"))
+	assert.ErrorIs(t, err, ErrSharedDeduplicationID)
+
+	_, err = publisher.PublishBatch(context.Background(), []interface{}{"foo"}, WithMessageGroupID("tenant-1"), WithDeduplicationID("foo"))
+	require.Nil(t, err)
+	require.Len(t, client.batches, 1)
+	assert.Equal(t, "foo", aws.ToString(client.batches[0].Entries[0].MessageDeduplicationId))
+}
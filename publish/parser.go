@@ -2,13 +2,34 @@ package publish
 
 import (
 	"encoding/json"
-	"github.com/gofrs/uuid"
+	"io"
+	"mime"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/codec"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 type DefaultMessageParser struct {
-	DefaultMessageType    string
+	// DefaultMessageType is used if the type cannot be derived from the message, see codec.TypeRegistry.TypeName
+	DefaultMessageType string
+	// Types maps Go types to message type names, if not set types are named by MessageType method or Go type name
+	Types *codec.TypeRegistry
+	// DefaultMessageGroupID is used if the group id cannot be derived from the message. It is empty by default, so
+	// such messages fail publishing to FIFO queues with ErrMissingMessageGroup unless a fallback group is chosen.
 	DefaultMessageGroupID string
-	DefaultContentType    string
+	// DefaultContentType is sent with messages encoded by the default JSON codec
+	DefaultContentType string
+	// DefaultRawContentType is sent with string, []byte and io.Reader messages which are passed through verbatim,
+	// text/plain if empty. It can be overridden per call with WithContentType.
+	DefaultRawContentType string
+	// Codec encodes the messages, JSON if not set
+	Codec codec.Codec
+	// GroupKey extracts the group id from messages neither implementing GroupKeyer nor having a field tagged `sqs:"group"`
+	GroupKey GroupKeyFunc
+	// Deduplication creates the deduplication id for FIFO queues, RandomDeduplication if not set
+	Deduplication DeduplicationStrategy
 }
 
 type MessageParams struct {
@@ -17,34 +38,159 @@ type MessageParams struct {
 	DeduplicationID string
 	ContentType     string
 	Body            string
+	// Attributes are sent along the Message-Type and Content-Type attributes, which they cannot override
+	Attributes map[string]types.MessageAttributeValue
+	// SystemAttributes are passed to SQS as is, currently only AWSTraceHeader is supported
+	SystemAttributes map[string]types.MessageSystemAttributeValue
+	// DelaySeconds overrides the queue delay for the message, not supported by FIFO queues
+	DelaySeconds int32
+	// DeliverAt schedules the message, it is sent with a Deliver-At attribute and delayed as far as SQS allows
+	DeliverAt time.Time
+	// Codec the body is encoded with, setting it with WithCodec chooses the codec for parsing
+	Codec codec.Codec
+}
+
+// CodecParser is implemented by parsers able to encode messages with a codec chosen per call
+type CodecParser interface {
+	ParseWithCodec(message interface{}, c codec.Codec) (MessageParams, error)
+}
+
+// AttributeProvider can be implemented by messages to add own attributes when parsed by DefaultMessageParser
+type AttributeProvider interface {
+	MessageAttributes() map[string]types.MessageAttributeValue
+}
+
+func StringAttribute(value string) types.MessageAttributeValue {
+	return types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+}
+
+func NumberAttribute(value string) types.MessageAttributeValue {
+	return types.MessageAttributeValue{DataType: aws.String("Number"), StringValue: aws.String(value)}
+}
+
+func BinaryAttribute(value []byte) types.MessageAttributeValue {
+	return types.MessageAttributeValue{DataType: aws.String("Binary"), BinaryValue: value}
 }
 
 func NewDefaultMessageParser() *DefaultMessageParser {
 	return &DefaultMessageParser{
-		DefaultMessageType:    "-",
-		DefaultMessageGroupID: "default",
-		DefaultContentType:    "application/json",
+		DefaultMessageType: "-",
+		DefaultContentType: "application/json",
+		Deduplication:      RandomDeduplication{},
 	}
 }
 
 func (d DefaultMessageParser) Parse(message interface{}) (params MessageParams, err error) {
-	raw, err := json.Marshal(message)
+	return d.ParseWithCodec(message, nil)
+}
+
+// ParseWithCodec parses the message encoding it with the given codec instead of the configured one
+func (d DefaultMessageParser) ParseWithCodec(message interface{}, c codec.Codec) (params MessageParams, err error) {
+	body, contentType, c, err := d.encode(message, c)
 	if err != nil {
 		return
 	}
 
-	// due to 5 minuted hardcoded time by AWS we do not really want deduplication
-	// but need to provide id when wanting to use MessageGroupId, so just want a unique id
-	deduplicationID, err := uuid.NewV4()
+	params.MessageType = d.messageType(message)
+	params.MessageGroupID = groupKey(message, d.GroupKey)
+	if params.MessageGroupID == "" {
+		params.MessageGroupID = d.DefaultMessageGroupID
+	}
+	params.Body = body
+	params.ContentType = contentType
+	params.Codec = c
+
+	// a deduplication id is required for FIFO queues without content based deduplication
+	deduplication := d.Deduplication
+	if deduplication == nil {
+		deduplication = RandomDeduplication{}
+	}
+	params.DeduplicationID, err = deduplication.DeduplicationID(message, params)
 	if err != nil {
 		return
 	}
 
-	params.MessageType = d.DefaultMessageType
-	params.MessageGroupID = d.DefaultMessageGroupID
-	params.DeduplicationID = deduplicationID.String()
-	params.Body = string(raw)
-	params.ContentType = d.DefaultContentType
+	if provider, ok := message.(AttributeProvider); ok {
+		params.Attributes = provider.MessageAttributes()
+	}
 
 	return
 }
+
+// encode marshals the message with the codec, if none is given raw payloads are passed through and others use the configured codec
+func (d DefaultMessageParser) encode(message interface{}, c codec.Codec) (string, string, codec.Codec, error) {
+	if c == nil {
+		if body, contentType, ok, err := d.passthrough(message); ok || err != nil {
+			return body, contentType, rawCodec(contentType), err
+		}
+	}
+
+	contentType := ""
+	if c == nil {
+		c = d.Codec
+	}
+	if c == nil {
+		c = codec.JSON
+		contentType = d.DefaultContentType
+	}
+	if contentType == "" {
+		contentType = c.ContentType()
+	}
+
+	body, err := c.Marshal(message)
+
+	return body, contentType, c, err
+}
+
+// passthrough returns pre-serialized payloads verbatim
+func (d DefaultMessageParser) passthrough(message interface{}) (string, string, bool, error) {
+	contentType := d.DefaultRawContentType
+	if contentType == "" {
+		contentType = codec.Text.ContentType()
+	}
+
+	switch raw := message.(type) {
+	case json.RawMessage:
+		return string(raw), codec.JSON.ContentType(), true, nil
+	case string:
+		return raw, contentType, true, nil
+	case []byte:
+		return string(raw), contentType, true, nil
+	case io.Reader:
+		body, err := io.ReadAll(raw)
+		return string(body), contentType, true, err
+	}
+
+	return "", "", false, nil
+}
+
+// rawCodec returns the codec decoding pre-serialized payloads of the content type, JSON payloads are decoded as JSON
+func rawCodec(contentType string) codec.Codec {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == codec.JSON.ContentType() {
+		return codec.JSON
+	}
+
+	return codec.Text
+}
+
+// messageType derives the type name of the message, pre-serialized payloads only have a type if implementing codec.MessageTyper
+func (d DefaultMessageParser) messageType(message interface{}) string {
+	if _, typed := message.(codec.MessageTyper); !typed && isRaw(message) {
+		return d.DefaultMessageType
+	}
+
+	if name, ok := d.Types.TypeName(message); ok {
+		return name
+	}
+
+	return d.DefaultMessageType
+}
+
+func isRaw(message interface{}) bool {
+	switch message.(type) {
+	case json.RawMessage, string, []byte, io.Reader:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,121 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/codec"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultMessageParser_Codec(t *testing.T) {
+	params, err := NewDefaultMessageParser().Parse(map[string]string{"foo": "bar"})
+	require.Nil(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, params.Body)
+	assert.Equal(t, "application/json", params.ContentType)
+
+	parser := NewDefaultMessageParser()
+	parser.Codec = codec.MessagePack
+	params, err = parser.Parse(map[string]string{"foo": "bar"})
+	require.Nil(t, err)
+	assert.Equal(t, "application/msgpack", params.ContentType)
+
+	decoded := map[string]string{}
+	require.Nil(t, codec.MessagePack.Unmarshal(params.Body, &decoded))
+	assert.Equal(t, map[string]string{"foo": "bar"}, decoded)
+}
+
+func TestPublisher_PublishWithCodec(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	_, err = publisher.Publish(context.Background(), []byte{0, 1, 2}, WithCodec(codec.Binary))
+	require.Nil(t, err)
+
+	require.Len(t, client.sent, 1)
+	assert.Equal(t, "AAEC", aws.ToString(client.sent[0].MessageBody))
+	assert.Equal(t, StringAttribute("application/octet-stream"), client.sent[0].MessageAttributes["Content-Type"])
+}
+
+func TestDefaultMessageParser_Passthrough(t *testing.T) {
+	parser := NewDefaultMessageParser()
+
+	params, err := parser.Parse(json.RawMessage(`{"foo":"bar"}`))
+	require.Nil(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, params.Body)
+	assert.Equal(t, "application/json", params.ContentType)
+	assert.Equal(t, codec.JSON, params.Codec)
+
+	for _, message := range []interface{}{"foo", []byte("foo"), strings.NewReader("foo")} {
+		params, err := parser.Parse(message)
+		require.Nil(t, err)
+		assert.Equal(t, "foo", params.Body, "%T", message)
+		assert.Equal(t, "text/plain", params.ContentType, "%T", message)
+		assert.Equal(t, codec.Text, params.Codec, "%T", message)
+	}
+
+	parser.DefaultRawContentType = "application/json; charset=utf-8"
+	params, err = parser.Parse(`{"foo":"bar"}`)
+	require.Nil(t, err)
+	assert.Equal(t, `{"foo":"bar"}`, params.Body)
+	assert.Equal(t, "application/json; charset=utf-8", params.ContentType)
+	assert.Equal(t, codec.JSON, params.Codec)
+
+	params, err = parser.ParseWithCodec("foo", codec.JSON)
+	require.Nil(t, err)
+	assert.Equal(t, `"foo"`, params.Body)
+}
+
+func TestPublisher_PublishRawWithContentType(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	_, err = publisher.Publish(context.Background(), "<foo/>", WithContentType("application/xml"))
+	require.Nil(t, err)
+
+	require.Len(t, client.sent, 1)
+	assert.Equal(t, "<foo/>", aws.ToString(client.sent[0].MessageBody))
+	assert.Equal(t, StringAttribute("application/xml"), client.sent[0].MessageAttributes["Content-Type"])
+}
+
+type priceChanged struct {
+	SKU string
+}
+
+func (priceChanged) MessageType() string {
+	return "price-changed"
+}
+
+type stockChanged struct {
+	SKU string
+}
+
+func TestDefaultMessageParser_MessageType(t *testing.T) {
+	parser := NewDefaultMessageParser()
+
+	params, err := parser.Parse(priceChanged{})
+	require.Nil(t, err)
+	assert.Equal(t, "price-changed", params.MessageType)
+
+	params, err = parser.Parse(&stockChanged{})
+	require.Nil(t, err)
+	assert.Equal(t, "git.limango.tech/shop-catalog/libraries/sqs-queue.git/publish.stockChanged", params.MessageType)
+
+	parser.Types = codec.NewTypeRegistry()
+	parser.Types.Register("stock-changed", stockChanged{})
+	params, err = parser.Parse(&stockChanged{})
+	require.Nil(t, err)
+	assert.Equal(t, "stock-changed", params.MessageType)
+
+	for _, message := range []interface{}{map[string]string{}, json.RawMessage(`{}`), strings.NewReader("foo")} {
+		params, err = parser.Parse(message)
+		require.Nil(t, err)
+		assert.Equal(t, "-", params.MessageType, "%T", message)
+	}
+}
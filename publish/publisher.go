@@ -2,16 +2,24 @@ package publish
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/schema"
 	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
-	"github.com/gofrs/uuid"
 	"github.com/labstack/gommon/log"
+	"maps"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+var ErrMissingMessageGroup = errors.New("message group id is required for FIFO queues")
+
 type SQSPublisher interface {
 	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
 	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
@@ -23,115 +31,360 @@ type MessageParser interface {
 }
 
 type Publisher struct {
-	QueueURL string
-	IsFIFO   bool
-	Parser   MessageParser
-	client   SQSPublisher
+	QueueURL   string
+	IsFIFO     bool
+	Parser     MessageParser
+	Schemas    *schema.Registry
+	Compressor *Compressor
+	Encrypter  *Encrypter
+	Offloader  *Offloader
+	Retry      *RetryPolicy
+	client     SQSPublisher
+	// resolver resolves the queue URL on first use if QueueURL is empty
+	resolver *utils.QueueURL
 }
 
 func NewPublisher(config PublisherConfig, client SQSPublisher) (*Publisher, error) {
-	queueURL, err := utils.GetQueueURL(client, config.QueueName)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Publisher{
-		QueueURL: *queueURL,
+	publisher := &Publisher{
 		IsFIFO:   config.IsFIFO,
 		Parser:   NewDefaultMessageParser(),
 		client:   client,
-	}, nil
+		resolver: utils.NewQueueURL(client, config.QueueName, config.QueueOwnerAWSAccountID),
+	}
+
+	if !config.LazyQueueURL {
+		queueURL, err := publisher.resolver.Get(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		publisher.QueueURL = queueURL
+	}
+
+	return publisher, nil
+}
+
+// queueURL returns QueueURL, publishers created with LazyQueueURL resolve it on first use
+func (p *Publisher) queueURL(ctx context.Context) (string, error) {
+	if p.QueueURL != "" || p.resolver == nil {
+		return p.QueueURL, nil
+	}
+
+	return p.resolver.Get(ctx)
 }
 
-func (p *Publisher) Publish(ctx context.Context, message interface{}) (err error) {
-	input, err := p.createSendMessageInput(message)
+// WithQueueURL returns a copy of the publisher sending to another queue, FIFO if the URL names a FIFO queue
+func (p *Publisher) WithQueueURL(queueURL string) *Publisher {
+	clone := *p
+	clone.QueueURL = queueURL
+	clone.IsFIFO = strings.HasSuffix(queueURL, ".fifo")
+
+	return &clone
+}
+
+// Publish sends the message and returns its id and sequence number assigned by SQS. A checksum mismatch is no error,
+// the message got accepted nevertheless, the result carries it as ChecksumErr.
+func (p *Publisher) Publish(ctx context.Context, message interface{}, opts ...PublishOption) (*Result, error) {
+	input, err := p.createSendMessageInput(ctx, message, opts)
 	if err != nil {
-		return
+		return nil, err
 	}
 
 	output, err := p.client.SendMessage(ctx, input)
-	if err == nil {
-		log.Debugf("published message %s", output.MessageId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newResult(output)
+	result.ChecksumErr = result.verify(aws.ToString(input.MessageBody), input.MessageAttributes)
+	if result.ChecksumErr != nil {
+		log.Warnf("published message %s: %s", result.MessageID, result.ChecksumErr)
+	} else {
+		log.Debugf("published message %s", result.MessageID)
+	}
+
+	return result, nil
+}
+
+// Prepare parses and encodes the message to the input Publish would send, e.g. to store it in an outbox.
+// Like Publish it calls the key provider of the Encrypter and stores offloaded bodies.
+func (p *Publisher) Prepare(ctx context.Context, message interface{}, opts ...PublishOption) (*sqs.SendMessageInput, error) {
+	return p.createSendMessageInput(ctx, message, opts)
+}
+
+// PublishPrepared sends inputs created by Prepare in batches to their queues and returns the results of the sent ones.
+// Failures are reported by a PartialError, the messages of its failures are the failed inputs.
+func (p *Publisher) PublishPrepared(ctx context.Context, inputs []*sqs.SendMessageInput) ([]Result, error) {
+	byQueue := map[string][]types.SendMessageBatchRequestEntry{}
+	queues := []string{}
+	for i, input := range inputs {
+		queueURL := aws.ToString(input.QueueUrl)
+		if _, ok := byQueue[queueURL]; !ok {
+			queues = append(queues, queueURL)
+		}
+
+		byQueue[queueURL] = append(byQueue[queueURL], types.SendMessageBatchRequestEntry{
+			Id:                      aws.String(strconv.Itoa(i)),
+			MessageBody:             input.MessageBody,
+			MessageGroupId:          input.MessageGroupId,
+			MessageDeduplicationId:  input.MessageDeduplicationId,
+			MessageAttributes:       input.MessageAttributes,
+			MessageSystemAttributes: input.MessageSystemAttributes,
+			DelaySeconds:            input.DelaySeconds,
+		})
+	}
+
+	result := &batchResult{}
+	for _, queueURL := range queues {
+		result.append(p.send(ctx, queueURL, byQueue[queueURL]))
+	}
+
+	messages := []interface{}{}
+	for _, input := range inputs {
+		messages = append(messages, input)
+	}
+
+	return result.results(), result.err(messages)
+}
+
+// PublishBatch publishes the messages in as many requests as the SQS batch limits require, options apply to every message.
+// The results of the published messages are returned ordered by Index, the position of the message in messages.
+// Failures of single messages or requests are reported by a PartialError covering all requests. Messages accepted
+// with mismatching checksums are no failures, their results carry the ChecksumErr.
+func (p *Publisher) PublishBatch(ctx context.Context, messages []interface{}, opts ...PublishOption) ([]Result, error) {
+	if err := checkBatchOptions(messages, opts); err != nil {
+		return nil, err
+	}
+
+	entries, err := p.createSendMessageEntries(ctx, messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	queueURL, err := p.queueURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := p.send(ctx, queueURL, entries)
+
+	return result.results(), result.err(messages)
+}
+
+// send sends the entries and retries failed ones according to the retry policy. Chunks for FIFO queues are sent one
+// after another, each with its retries, so messages of a group arrive in order.
+func (p *Publisher) send(ctx context.Context, queueURL string, entries []types.SendMessageBatchRequestEntry) *batchResult {
+	if !p.isFIFO(queueURL) {
+		return p.sendWithRetry(ctx, queueURL, entries)
+	}
+
+	result := &batchResult{}
+	for _, chunk := range chunkEntries(entries) {
+		result.append(p.sendWithRetry(ctx, queueURL, chunk))
+	}
+
+	return result
+}
+
+func (p *Publisher) sendWithRetry(ctx context.Context, queueURL string, entries []types.SendMessageBatchRequestEntry) *batchResult {
+	result := p.sendEntries(ctx, queueURL, entries)
+	if p.Retry != nil {
+		p.retryFailed(ctx, queueURL, entries, result)
+	}
+
+	return result
+}
+
+// isFIFO tells whether the queue is a FIFO queue, prepared inputs may name other queues than the publisher
+func (p *Publisher) isFIFO(queueURL string) bool {
+	return p.IsFIFO || strings.HasSuffix(queueURL, ".fifo")
+}
+
+// sendEntries sends the entries chunked to valid batches, concurrently unless the queue is a FIFO queue
+func (p *Publisher) sendEntries(ctx context.Context, queueURL string, entries []types.SendMessageBatchRequestEntry) *batchResult {
+	parallel := parallelRequests
+	if p.isFIFO(queueURL) {
+		parallel = 1
 	}
+	semaphore := make(chan int, parallel)
+	defer close(semaphore)
 
-	return
+	wg := &sync.WaitGroup{}
+	result := &batchResult{}
+
+	for _, chunk := range chunkEntries(entries) {
+		semaphore <- 1
+		wg.Add(1)
+
+		go func(chunk []types.SendMessageBatchRequestEntry) {
+			input := &sqs.SendMessageBatchInput{
+				Entries:  chunk,
+				QueueUrl: aws.String(queueURL),
+			}
+
+			output, err := p.client.SendMessageBatch(ctx, input)
+			result.add(chunk, output, err)
+
+			<-semaphore
+			wg.Done()
+		}(chunk)
+	}
+	wg.Wait()
+
+	return result
+}
+
+// preparedMessage is a parsed message with body and attributes ready to be sent
+type preparedMessage struct {
+	params     MessageParams
+	body       string
+	attributes map[string]types.MessageAttributeValue
 }
 
-func (p *Publisher) PublishBatch(ctx context.Context, messages []interface{}) error {
-	entries, err := p.createSendMessageEntries(messages)
+func (p *Publisher) prepare(ctx context.Context, message interface{}, opts []PublishOption) (*preparedMessage, error) {
+	params, err := resolveParams(p.Parser, message, opts)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if p.IsFIFO && params.MessageGroupID == "" {
+		return nil, ErrMissingMessageGroup
+	}
+
+	if p.Schemas != nil {
+		if err = p.Schemas.ValidateContent(params.MessageType, params.ContentType, params.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	prepared := &preparedMessage{
+		params:     params,
+		body:       params.Body,
+		attributes: map[string]types.MessageAttributeValue{},
 	}
 
-	input := &sqs.SendMessageBatchInput{
-		Entries:  entries,
-		QueueUrl: aws.String(p.QueueURL),
+	for name, value := range params.Attributes {
+		prepared.attributes[name] = value
+	}
+	prepared.attributes[utils.MessageTypeAttribute] = StringAttribute(params.MessageType)
+	prepared.attributes[utils.ContentTypeAttribute] = StringAttribute(params.ContentType)
+	if !params.DeliverAt.IsZero() {
+		prepared.attributes[utils.DeliverAtAttribute] = StringAttribute(params.DeliverAt.UTC().Format(time.RFC3339Nano))
+		// FIFO queues do not support delaying single messages
+		if !p.IsFIFO && prepared.params.DelaySeconds == 0 {
+			prepared.params.DelaySeconds = delaySeconds(params.DeliverAt)
+		}
 	}
 
-	output, err := p.client.SendMessageBatch(ctx, input)
-	if err == nil {
-		for _, o := range output.Successful {
-			log.Debugf("published message %s", o.MessageId)
+	if p.Compressor != nil {
+		if err = p.Compressor.compress(prepared); err != nil {
+			return nil, err
 		}
+	}
 
-		if len(output.Failed) > 0 {
-			return NewPartialError(output)
+	if p.Encrypter != nil {
+		if err = p.Encrypter.encrypt(ctx, prepared); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Offloader != nil {
+		if err = p.Offloader.offload(ctx, prepared); err != nil {
+			return nil, err
 		}
 	}
 
-	return err
+	return prepared, nil
+}
+
+// resolveParams parses the message and applies the options to the parameters
+func resolveParams(parser MessageParser, message interface{}, opts []PublishOption) (MessageParams, error) {
+	params, err := parse(parser, message, opts)
+	if err != nil {
+		return params, err
+	}
+
+	// options must not modify maps owned by parser or message
+	params.Attributes = maps.Clone(params.Attributes)
+	params.SystemAttributes = maps.Clone(params.SystemAttributes)
+	for _, opt := range opts {
+		opt(&params)
+	}
+
+	return params, nil
+}
+
+// parse parses the message with the parser, using the codec chosen by the options if any
+func parse(parser MessageParser, message interface{}, opts []PublishOption) (MessageParams, error) {
+	requested := MessageParams{}
+	for _, opt := range opts {
+		opt(&requested)
+	}
+
+	if requested.Codec == nil {
+		return parser.Parse(message)
+	}
+
+	codecParser, ok := parser.(CodecParser)
+	if !ok {
+		return MessageParams{}, fmt.Errorf("parser %T does not support choosing a codec", parser)
+	}
+
+	return codecParser.ParseWithCodec(message, requested.Codec)
 }
 
-func (p *Publisher) createSendMessageInput(message interface{}) (*sqs.SendMessageInput, error) {
-	params, err := p.Parser.Parse(message)
+func (p *Publisher) createSendMessageInput(ctx context.Context, message interface{}, opts []PublishOption) (*sqs.SendMessageInput, error) {
+	prepared, err := p.prepare(ctx, message, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	queueURL, err := p.queueURL(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	return &sqs.SendMessageInput{
-		QueueUrl:               aws.String(p.QueueURL),
-		MessageBody:            aws.String(params.Body),
-		MessageGroupId:         p.fifoOnly(params.MessageGroupID),
-		MessageDeduplicationId: p.fifoOnly(params.DeduplicationID),
-		MessageAttributes: map[string]types.MessageAttributeValue{
-			"Message-Type": {DataType: aws.String("String"), StringValue: aws.String(params.MessageType)},
-			"Content-Type": {DataType: aws.String("String"), StringValue: aws.String(params.ContentType)},
-		},
+		QueueUrl:                aws.String(queueURL),
+		MessageBody:             aws.String(prepared.body),
+		MessageGroupId:          p.fifoOnly(prepared.params.MessageGroupID),
+		MessageDeduplicationId:  p.fifoOnly(prepared.params.DeduplicationID),
+		MessageAttributes:       prepared.attributes,
+		MessageSystemAttributes: prepared.params.SystemAttributes,
+		DelaySeconds:            prepared.params.DelaySeconds,
 	}, nil
 }
 
-func (p *Publisher) createSendMessageEntries(messages []interface{}) ([]types.SendMessageBatchRequestEntry, error) {
+func (p *Publisher) createSendMessageEntries(ctx context.Context, messages []interface{}, opts []PublishOption) ([]types.SendMessageBatchRequestEntry, error) {
 	entries := []types.SendMessageBatchRequestEntry{}
 
-	for _, m := range messages {
-		entry, err := p.createSendMessageEntry(m)
+	for i, m := range messages {
+		entry, err := p.createSendMessageEntry(ctx, m, opts)
 		if err != nil {
 			return nil, err
 		}
 
+		// the index as id maps results and failures back to the messages
+		entry.Id = aws.String(strconv.Itoa(i))
 		entries = append(entries, *entry)
 	}
 
 	return entries, nil
 }
 
-func (p *Publisher) createSendMessageEntry(message interface{}) (*types.SendMessageBatchRequestEntry, error) {
-	params, err := p.Parser.Parse(message)
+func (p *Publisher) createSendMessageEntry(ctx context.Context, message interface{}, opts []PublishOption) (*types.SendMessageBatchRequestEntry, error) {
+	prepared, err := p.prepare(ctx, message, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// just need unique id for request
-	key, _ := uuid.NewV4()
+	// the id is set by the caller to the index of the message within its batch
 	return &types.SendMessageBatchRequestEntry{
-		Id:                     aws.String(key.String()),
-		MessageBody:            aws.String(params.Body),
-		MessageGroupId:         p.fifoOnly(params.MessageGroupID),
-		MessageDeduplicationId: p.fifoOnly(params.DeduplicationID),
-		MessageAttributes: map[string]types.MessageAttributeValue{
-			"Message-Type": {DataType: aws.String("String"), StringValue: aws.String(params.MessageType)},
-			"Content-Type": {DataType: aws.String("String"), StringValue: aws.String(params.ContentType)},
-		},
+		MessageBody:             aws.String(prepared.body),
+		MessageGroupId:          p.fifoOnly(prepared.params.MessageGroupID),
+		MessageDeduplicationId:  p.fifoOnly(prepared.params.DeduplicationID),
+		MessageAttributes:       prepared.attributes,
+		MessageSystemAttributes: prepared.params.SystemAttributes,
+		DelaySeconds:            prepared.params.DelaySeconds,
 	}, nil
 }
 
@@ -143,9 +396,28 @@ func (p *Publisher) fifoOnly(value string) *string {
 	}
 }
 
+// Failure describes a message of a batch that failed to publish
+type Failure struct {
+	// Index of the message in the published batch, -1 if the entry id reported by SQS maps to no message
+	Index int
+	// Message is the message as passed to the publisher, nil if Index is -1
+	Message     interface{}
+	Code        string
+	Reason      string
+	SenderFault bool
+}
+
+// Retryable reports whether resending the message may succeed, sender faults only may if throttled.
+// Messages accepted with mismatching checksums are no failures, resending them would publish them twice.
+func (f Failure) Retryable() bool {
+	return !f.SenderFault || throttlingCodes[f.Code]
+}
+
 type PartialError struct {
 	Total  int
 	Errors []types.BatchResultErrorEntry
+	// Failures maps the errors to the published messages, ordered by Index
+	Failures []Failure
 }
 
 func NewPartialError(output *sqs.SendMessageBatchOutput) PartialError {
@@ -155,6 +427,57 @@ func NewPartialError(output *sqs.SendMessageBatchOutput) PartialError {
 	}
 }
 
+// newPartialError creates the error for the failed entries, their ids are the indices of the messages
+func newPartialError(total int, failed []types.BatchResultErrorEntry, messages []interface{}) PartialError {
+	failures := []Failure{}
+	for _, f := range failed {
+		failure := Failure{
+			Index:       -1,
+			Code:        aws.ToString(f.Code),
+			Reason:      aws.ToString(f.Message),
+			SenderFault: f.SenderFault,
+		}
+		if index, ok := entryIndex(f.Id); ok && index < len(messages) {
+			failure.Index, failure.Message = index, messages[index]
+		}
+
+		failures = append(failures, failure)
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Index < failures[j].Index
+	})
+
+	return PartialError{
+		Total:    total,
+		Errors:   failed,
+		Failures: failures,
+	}
+}
+
+// FailedMessages returns the messages that failed to publish in their original order, e.g. to resend them.
+// Failures not mapped to a message are left out.
+func (e PartialError) FailedMessages() []interface{} {
+	messages := []interface{}{}
+	for _, f := range e.Failures {
+		if f.Index >= 0 {
+			messages = append(messages, f.Message)
+		}
+	}
+
+	return messages
+}
+
+// Retryable reports whether resending all failed messages may succeed
+func (e PartialError) Retryable() bool {
+	for _, f := range e.Failures {
+		if !f.Retryable() {
+			return false
+		}
+	}
+
+	return len(e.Failures) > 0
+}
+
 func (e PartialError) Error() string {
 	return fmt.Sprintf("%d of %d messages failed to publish:\n%s", len(e.Errors), e.Total, e.stringifyErrors())
 }
@@ -163,7 +486,10 @@ func (e PartialError) stringifyErrors() string {
 	errors := []string{}
 
 	for _, err := range e.Errors {
-		errors = append(errors, fmt.Sprintf("%s [Code:%s,EntryId:%s]", *err.Message, *err.Code, *err.Id))
+		errors = append(errors, fmt.Sprintf(
+			"%s [Code:%s,EntryId:%s,SenderFault:%t]",
+			aws.ToString(err.Message), aws.ToString(err.Code), aws.ToString(err.Id), err.SenderFault,
+		))
 	}
 
 	return strings.Join(errors, ", ")
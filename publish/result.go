@@ -0,0 +1,80 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// ErrChecksumMismatch is returned if the MD5 digests reported by SQS differ from the ones of the sent message
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Result describes a message accepted by SQS
+type Result struct {
+	// Index of the message in the published batch, 0 for single messages and -1 if SQS returned an unknown entry id
+	Index     int
+	MessageID string
+	// SequenceNumber is assigned by FIFO queues only
+	SequenceNumber               string
+	MD5OfMessageBody             string
+	MD5OfMessageAttributes       string
+	MD5OfMessageSystemAttributes string
+	// ChecksumErr wraps ErrChecksumMismatch if the digests reported by SQS differ from the ones of the sent message.
+	// SQS accepted the message nevertheless, resending it would publish it twice.
+	ChecksumErr error
+}
+
+func newResult(output *sqs.SendMessageOutput) *Result {
+	return &Result{
+		MessageID:                    aws.ToString(output.MessageId),
+		SequenceNumber:               aws.ToString(output.SequenceNumber),
+		MD5OfMessageBody:             aws.ToString(output.MD5OfMessageBody),
+		MD5OfMessageAttributes:       aws.ToString(output.MD5OfMessageAttributes),
+		MD5OfMessageSystemAttributes: aws.ToString(output.MD5OfMessageSystemAttributes),
+	}
+}
+
+func newEntryResult(entry types.SendMessageBatchResultEntry) Result {
+	index, ok := entryIndex(entry.Id)
+	if !ok {
+		index = -1
+	}
+
+	return Result{
+		Index:                        index,
+		MessageID:                    aws.ToString(entry.MessageId),
+		SequenceNumber:               aws.ToString(entry.SequenceNumber),
+		MD5OfMessageBody:             aws.ToString(entry.MD5OfMessageBody),
+		MD5OfMessageAttributes:       aws.ToString(entry.MD5OfMessageAttributes),
+		MD5OfMessageSystemAttributes: aws.ToString(entry.MD5OfMessageSystemAttributes),
+	}
+}
+
+// entryIndex returns the index of the message an entry id was created for, false if the id is no index
+func entryIndex(id *string) (int, bool) {
+	index, err := strconv.Atoi(aws.ToString(id))
+	if id == nil || err != nil || index < 0 {
+		return 0, false
+	}
+
+	return index, true
+}
+
+// verify compares the digests reported by SQS, if any, with the ones of the sent body and attributes
+func (r Result) verify(body string, attributes map[string]types.MessageAttributeValue) error {
+	if r.MD5OfMessageBody != "" && !strings.EqualFold(r.MD5OfMessageBody, utils.BodyMD5(body)) {
+		return fmt.Errorf("%w: body of message %s", ErrChecksumMismatch, r.MessageID)
+	}
+
+	if r.MD5OfMessageAttributes != "" && !strings.EqualFold(r.MD5OfMessageAttributes, utils.AttributesMD5(attributes)) {
+		return fmt.Errorf("%w: attributes of message %s", ErrChecksumMismatch, r.MessageID)
+	}
+
+	return nil
+}
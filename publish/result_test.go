@@ -0,0 +1,106 @@
+package publish
+
+import (
+	"context"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisher_PublishReturnsResult(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", checksums: true}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	result, err := publisher.Publish(context.Background(), "bar", WithStringAttribute("foo", "baz"))
+	require.Nil(t, err)
+
+	assert.Equal(t, "foo", result.MessageID)
+	assert.Equal(t, "1", result.SequenceNumber)
+	assert.Equal(t, utils.BodyMD5("bar"), result.MD5OfMessageBody)
+	assert.NotEmpty(t, result.MD5OfMessageAttributes)
+}
+
+func TestPublisher_PublishDetectsChecksumMismatch(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", checksums: true, badChecksum: true}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	result, err := publisher.Publish(context.Background(), "bar")
+
+	require.Nil(t, err)
+	assert.ErrorIs(t, result.ChecksumErr, ErrChecksumMismatch)
+	assert.Equal(t, "foo", result.MessageID)
+}
+
+func TestPublisher_PublishBatchReturnsResultsByIndex(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo", checksums: true}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	messages := []interface{}{}
+	for i := 0; i < 12; i++ {
+		messages = append(messages, "bar")
+	}
+	messages[3] = "foo"
+
+	results, err := publisher.PublishBatch(context.Background(), messages)
+
+	require.IsType(t, PartialError{}, err)
+	require.Len(t, results, 11)
+	for i, result := range results {
+		index := i
+		if i >= 3 {
+			index++
+		}
+		assert.Equal(t, index, result.Index)
+		assert.Equal(t, "message-"+strconv.Itoa(index), result.MessageID)
+	}
+}
+
+func TestPublisher_PublishBatchReportsChecksumMismatch(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", checksums: true, badChecksum: true}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	results, err := publisher.PublishBatch(context.Background(), []interface{}{"foo", "bar"})
+
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.ErrorIs(t, result.ChecksumErr, ErrChecksumMismatch)
+	}
+	require.Len(t, client.batches, 1)
+}
+
+func TestPublisher_PublishBatchDoesNotRetryChecksumMismatch(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", checksums: true, badChecksum: true}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+	publisher.Retry = &RetryPolicy{MaxAttempts: 3}
+
+	results, err := publisher.PublishBatch(context.Background(), []interface{}{"foo"})
+
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.ErrorIs(t, results[0].ChecksumErr, ErrChecksumMismatch)
+	assert.Len(t, client.batches, 1)
+}
+
+func TestAttributesMD5_IgnoresOrder(t *testing.T) {
+	a := map[string]types.MessageAttributeValue{
+		"foo": StringAttribute("bar"),
+		"baz": BinaryAttribute([]byte{1, 2}),
+	}
+	b := map[string]types.MessageAttributeValue{
+		"baz": BinaryAttribute([]byte{1, 2}),
+		"foo": StringAttribute("bar"),
+	}
+
+	assert.Equal(t, utils.AttributesMD5(a), utils.AttributesMD5(b))
+	assert.NotEqual(t, utils.AttributesMD5(a), utils.AttributesMD5(map[string]types.MessageAttributeValue{"foo": StringAttribute("bar")}))
+}
@@ -0,0 +1,101 @@
+package publish
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/labstack/gommon/log"
+)
+
+// throttlingCodes are error codes worth retrying even if SQS reports them as sender fault
+var throttlingCodes = map[string]bool{
+	"Throttling":          true,
+	"ThrottlingException": true,
+	"RequestThrottled":    true,
+	"AWS.SimpleQueueService.RequestThrottled": true,
+}
+
+// RetryPolicy configures resending of entries that failed in PublishBatch.
+// Only entries SQS failed without sender fault or by throttling are resent. Requests failed as a whole are not,
+// the retryer of the SDK client has retried them already if the error is transient.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled for each further retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to this fraction in both directions
+	Jitter float64
+}
+
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     100 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// delay returns the time to wait before the given retry, starting with 1
+func (r *RetryPolicy) delay(retry int) time.Duration {
+	delay := r.Backoff
+	for i := 1; i < retry && delay < r.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if r.MaxBackoff > 0 && delay > r.MaxBackoff {
+		delay = r.MaxBackoff
+	}
+
+	if r.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * r.Jitter * float64(delay))
+	}
+
+	return delay
+}
+
+// retryable tells whether SQS reported the entry to have failed transiently
+func retryable(entry types.BatchResultErrorEntry) bool {
+	if aws.ToString(entry.Code) == requestFailedCode {
+		return false
+	}
+
+	return !entry.SenderFault || throttlingCodes[aws.ToString(entry.Code)]
+}
+
+// retryFailed resends the retryable failures of the result till they succeed or the attempts are exhausted
+func (p *Publisher) retryFailed(ctx context.Context, queueURL string, entries []types.SendMessageBatchRequestEntry, result *batchResult) {
+	byID := map[string]types.SendMessageBatchRequestEntry{}
+	for _, entry := range entries {
+		byID[aws.ToString(entry.Id)] = entry
+	}
+
+	for attempt := 2; attempt <= p.Retry.MaxAttempts; attempt++ {
+		retry := []types.SendMessageBatchRequestEntry{}
+		remaining := []types.BatchResultErrorEntry{}
+		for _, failed := range result.failed {
+			entry, ok := byID[aws.ToString(failed.Id)]
+			if ok && retryable(failed) {
+				retry = append(retry, entry)
+			} else {
+				remaining = append(remaining, failed)
+			}
+		}
+
+		if len(retry) == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.Retry.delay(attempt - 1)):
+		}
+
+		log.Debugf("retrying %d failed messages, attempt %d of %d", len(retry), attempt, p.Retry.MaxAttempts)
+		result.merge(remaining, p.sendEntries(ctx, queueURL, retry))
+	}
+}
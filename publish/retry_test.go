@@ -0,0 +1,91 @@
+package publish
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRetryingPublisher(t *testing.T, client *MockClient) *Publisher {
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	publisher.Retry = &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	return publisher
+}
+
+func TestPublisher_PublishBatchRetriesFailedEntries(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo", failTimes: 2}
+	publisher := newRetryingPublisher(t, client)
+
+	_, err := publisher.PublishBatch(context.Background(), []interface{}{"foo", "bar"})
+	require.Nil(t, err)
+
+	require.Len(t, client.batches, 3)
+	assert.Len(t, client.batches[1].Entries, 1)
+	assert.Equal(t, "foo", aws.ToString(client.batches[2].Entries[0].MessageBody))
+}
+
+func TestPublisher_PublishBatchRetriesExhausted(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo"}
+	publisher := newRetryingPublisher(t, client)
+
+	_, err := publisher.PublishBatch(context.Background(), []interface{}{"foo", "bar"})
+
+	require.IsType(t, PartialError{}, err)
+	assert.Equal(t, 2, err.(PartialError).Total)
+	assert.Len(t, err.(PartialError).Errors, 1)
+	assert.Len(t, client.batches, 3)
+}
+
+func TestPublisher_PublishBatchRetriesThrottledSenderFault(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo", failTimes: 1, senderFault: true, failCode: "RequestThrottled"}
+	publisher := newRetryingPublisher(t, client)
+
+	_, err := publisher.PublishBatch(context.Background(), []interface{}{"foo"})
+	require.Nil(t, err)
+	assert.Len(t, client.batches, 2)
+}
+
+func TestPublisher_PublishBatchSkipsSenderFault(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo", senderFault: true, failCode: "InvalidParameterValue"}
+	publisher := newRetryingPublisher(t, client)
+
+	_, err := publisher.PublishBatch(context.Background(), []interface{}{"foo", "bar"})
+
+	require.IsType(t, PartialError{}, err)
+	assert.Len(t, client.batches, 1)
+}
+
+func TestPublisher_PublishBatchSkipsRequestErr(t *testing.T) {
+	sendErr := &smithy.GenericAPIError{Code: "AccessDenied", Message: "foo", Fault: smithy.FaultClient}
+	client := &MockClient{queueUrl: "https://foo.bar/baz", sendErr: sendErr}
+	publisher := newRetryingPublisher(t, client)
+
+	_, err := publisher.PublishBatch(context.Background(), []interface{}{"foo", "bar"})
+
+	assert.ErrorIs(t, err, sendErr)
+	assert.Len(t, client.batches, 1)
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := &RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, policy.delay(1))
+	assert.Equal(t, 200*time.Millisecond, policy.delay(2))
+	assert.Equal(t, 800*time.Millisecond, policy.delay(4))
+	assert.Equal(t, time.Second, policy.delay(10))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		delay := policy.delay(1)
+		assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+		assert.LessOrEqual(t, delay, 150*time.Millisecond)
+	}
+}
@@ -0,0 +1,263 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+var ErrNoRoute = errors.New("no route matches the message")
+
+// Matcher decides by the parsed parameters, options applied, whether a message takes a route
+type Matcher func(params MessageParams) bool
+
+// MatchType matches messages of any of the types
+func MatchType(messageTypes ...string) Matcher {
+	return func(params MessageParams) bool {
+		for _, t := range messageTypes {
+			if params.MessageType == t {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// MatchAttribute matches messages having a string or number attribute with the value
+func MatchAttribute(name string, value string) Matcher {
+	return func(params MessageParams) bool {
+		attribute, ok := params.Attributes[name]
+		return ok && attribute.StringValue != nil && *attribute.StringValue == value
+	}
+}
+
+// MatchAll matches every message
+func MatchAll() Matcher {
+	return func(MessageParams) bool {
+		return true
+	}
+}
+
+type route struct {
+	match        Matcher
+	destinations []*Publisher
+}
+
+// DestinationResult is the outcome of publishing to one destination queue
+type DestinationResult struct {
+	QueueURL string
+	// Results of the messages sent to the queue, indexed by their position in the published batch
+	Results []Result
+	Err     error
+}
+
+// RoutingPublisher publishes each message to the destinations of every matching route.
+// Batches are split per destination and sent concurrently.
+type RoutingPublisher struct {
+	// Parser derives the parameters the routes match on, the destinations parse messages with their own parsers
+	Parser MessageParser
+	routes []route
+	// Fallback receives messages no route matches, if not set these fail with ErrNoRoute
+	Fallback *Publisher
+}
+
+func NewRoutingPublisher() *RoutingPublisher {
+	return &RoutingPublisher{
+		Parser: NewDefaultMessageParser(),
+	}
+}
+
+// Route sends matching messages to the destinations, a message matching several routes goes to each destination once
+func (r *RoutingPublisher) Route(match Matcher, destinations ...*Publisher) *RoutingPublisher {
+	r.routes = append(r.routes, route{match: match, destinations: destinations})
+
+	return r
+}
+
+// Publish sends the message to all its destinations, failed destinations are joined to the error
+func (r *RoutingPublisher) Publish(ctx context.Context, message interface{}, opts ...PublishOption) ([]DestinationResult, error) {
+	return r.PublishBatch(ctx, []interface{}{message}, opts...)
+}
+
+// PublishBatch sends every destination its messages in one PublishBatch call, the results are aggregated per destination.
+// Indices of results and PartialError failures refer to messages, errors of destinations are joined.
+func (r *RoutingPublisher) PublishBatch(ctx context.Context, messages []interface{}, opts ...PublishOption) ([]DestinationResult, error) {
+	if err := checkBatchOptions(messages, opts); err != nil {
+		return nil, err
+	}
+
+	routed, err := newRoutedMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	destinations, batches, err := r.split(routed, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]DestinationResult, len(destinations))
+	wg := &sync.WaitGroup{}
+	for i, destination := range destinations {
+		wg.Add(1)
+		go func(i int, destination *Publisher) {
+			defer wg.Done()
+
+			// lazy destinations resolve their URL here, so the result names the queue
+			queueURL, err := destination.queueURL(ctx)
+			if err != nil {
+				results[i] = DestinationResult{Err: err}
+				return
+			}
+
+			indices := batches[destination]
+			batch := []interface{}{}
+			for _, index := range indices {
+				batch = append(batch, routed.message(index))
+			}
+
+			published, err := destination.PublishBatch(ctx, batch, opts...)
+			for j := range published {
+				if index := published[j].Index; index >= 0 && index < len(indices) {
+					published[j].Index = indices[index]
+				}
+			}
+
+			results[i] = DestinationResult{
+				QueueURL: queueURL,
+				Results:  published,
+				Err:      remapPartialError(err, indices, routed),
+			}
+		}(i, destination)
+	}
+	wg.Wait()
+
+	errs := []error{}
+	for _, result := range results {
+		switch {
+		case result.Err == nil:
+		case result.QueueURL == "":
+			// the URL of the queue failed to resolve
+			errs = append(errs, result.Err)
+		default:
+			errs = append(errs, fmt.Errorf("queue %s: %w", result.QueueURL, result.Err))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// split returns the destinations in order of first use and the indices of the messages each one gets
+func (r *RoutingPublisher) split(routed *routedMessages, opts []PublishOption) ([]*Publisher, map[*Publisher][]int, error) {
+	destinations := []*Publisher{}
+	batches := map[*Publisher][]int{}
+
+	for index := range routed.messages {
+		params, err := resolveParams(r.Parser, routed.message(index), opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		matched := r.destinations(params)
+		if len(matched) == 0 {
+			return nil, nil, fmt.Errorf("%w: type '%s'", ErrNoRoute, params.MessageType)
+		}
+
+		for _, destination := range matched {
+			if _, ok := batches[destination]; !ok {
+				destinations = append(destinations, destination)
+			}
+			batches[destination] = append(batches[destination], index)
+		}
+	}
+
+	return destinations, batches, nil
+}
+
+// routedMessages reads io.Reader messages once, so routing and every destination parse a reader of their own
+type routedMessages struct {
+	messages []interface{}
+	bodies   map[int][]byte
+}
+
+func newRoutedMessages(messages []interface{}) (*routedMessages, error) {
+	routed := &routedMessages{messages: messages, bodies: map[int][]byte{}}
+	for i, message := range messages {
+		if reader, ok := message.(io.Reader); ok {
+			body, err := io.ReadAll(reader)
+			if err != nil {
+				return nil, err
+			}
+			routed.bodies[i] = body
+		}
+	}
+
+	return routed, nil
+}
+
+// message returns the message at index, a fresh reader of the body for io.Reader messages
+func (m *routedMessages) message(index int) interface{} {
+	if body, ok := m.bodies[index]; ok {
+		return bytes.NewReader(body)
+	}
+
+	return m.messages[index]
+}
+
+// destinations returns the distinct destinations of all routes matching the parameters, the fallback if none matches
+func (r *RoutingPublisher) destinations(params MessageParams) []*Publisher {
+	seen := map[*Publisher]bool{}
+	destinations := []*Publisher{}
+
+	for _, route := range r.routes {
+		if !route.match(params) {
+			continue
+		}
+
+		for _, destination := range route.destinations {
+			if !seen[destination] {
+				seen[destination] = true
+				destinations = append(destinations, destination)
+			}
+		}
+	}
+
+	if len(destinations) == 0 && r.Fallback != nil {
+		destinations = append(destinations, r.Fallback)
+	}
+
+	return destinations
+}
+
+// remapPartialError translates the indices of a destination batch to the indices of the published messages.
+// The failures get the published messages, io.Reader ones as fresh readers, as the sent ones are read already.
+func remapPartialError(err error, indices []int, routed *routedMessages) error {
+	var partial PartialError
+	if !errors.As(err, &partial) {
+		return err
+	}
+
+	remapped := PartialError{Total: partial.Total}
+	for _, e := range partial.Errors {
+		if index, ok := entryIndex(e.Id); ok && index < len(indices) {
+			e.Id = aws.String(strconv.Itoa(indices[index]))
+		}
+		remapped.Errors = append(remapped.Errors, e)
+	}
+	for _, f := range partial.Failures {
+		if f.Index >= 0 && f.Index < len(indices) {
+			f.Index = indices[f.Index]
+			f.Message = routed.message(f.Index)
+		}
+		remapped.Failures = append(remapped.Failures, f)
+	}
+
+	return remapped
+}
@@ -0,0 +1,133 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRoutedPublisher(t *testing.T, client *MockClient) *Publisher {
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	return publisher
+}
+
+func TestRoutingPublisher_PublishBatchFansOut(t *testing.T) {
+	allClient := &MockClient{queueUrl: "https://foo.bar/all"}
+	priceClient := &MockClient{queueUrl: "https://foo.bar/prices"}
+	all, prices := newRoutedPublisher(t, allClient), newRoutedPublisher(t, priceClient)
+
+	router := NewRoutingPublisher().
+		Route(MatchAll(), all).
+		Route(MatchType("price"), prices, all)
+
+	messages := []interface{}{"foo", "bar", "baz"}
+	results, err := router.PublishBatch(context.Background(), messages, WithMessageType("price"))
+	require.Nil(t, err)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "https://foo.bar/all", results[0].QueueURL)
+	assert.Len(t, results[0].Results, 3)
+	assert.Equal(t, "https://foo.bar/prices", results[1].QueueURL)
+	assert.Len(t, results[1].Results, 3)
+
+	require.Len(t, allClient.batches, 1)
+	assert.Len(t, allClient.batches[0].Entries, 3)
+	require.Len(t, priceClient.batches, 1)
+}
+
+func TestRoutingPublisher_PublishReaderToSeveralDestinations(t *testing.T) {
+	firstClient := &MockClient{queueUrl: "https://foo.bar/first"}
+	secondClient := &MockClient{queueUrl: "https://foo.bar/second"}
+	router := NewRoutingPublisher().Route(MatchAll(), newRoutedPublisher(t, firstClient), newRoutedPublisher(t, secondClient))
+
+	_, err := router.Publish(context.Background(), strings.NewReader(`{"foo":"bar"}`))
+	require.Nil(t, err)
+
+	for _, client := range []*MockClient{firstClient, secondClient} {
+		require.Len(t, client.batches, 1)
+		assert.Equal(t, `{"foo":"bar"}`, aws.ToString(client.batches[0].Entries[0].MessageBody))
+	}
+}
+
+func TestRoutingPublisher_ResendFailedReader(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz", failBody: "foo", failTimes: 1}
+	router := NewRoutingPublisher().Route(MatchAll(), newRoutedPublisher(t, client))
+
+	_, err := router.PublishBatch(context.Background(), []interface{}{"bar", strings.NewReader("foo")})
+	var partial PartialError
+	require.ErrorAs(t, err, &partial)
+	require.Len(t, partial.Failures, 1)
+	assert.Equal(t, 1, partial.Failures[0].Index)
+
+	_, err = router.PublishBatch(context.Background(), partial.FailedMessages())
+	require.Nil(t, err)
+	require.Len(t, client.batches, 2)
+	assert.Equal(t, "foo", aws.ToString(client.batches[1].Entries[0].MessageBody))
+}
+
+func TestRoutingPublisher_PublishToLazyDestination(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/lazy"}
+	lazy, err := NewPublisher(PublisherConfig{QueueName: "lazy", LazyQueueURL: true}, client)
+	require.Nil(t, err)
+	require.Empty(t, lazy.QueueURL)
+
+	results, err := NewRoutingPublisher().Route(MatchAll(), lazy).Publish(context.Background(), "foo")
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "https://foo.bar/lazy", results[0].QueueURL)
+
+	client.queueUrl, client.queueUrlErr = "", errors.New("unavailable")
+	unresolved, err := NewPublisher(PublisherConfig{QueueName: "unresolved", LazyQueueURL: true}, client)
+	require.Nil(t, err)
+	_, err = NewRoutingPublisher().Route(MatchAll(), unresolved).Publish(context.Background(), "foo")
+	assert.EqualError(t, err, "unavailable")
+}
+
+func TestRoutingPublisher_PublishBatchRoutesByAttribute(t *testing.T) {
+	deClient := &MockClient{queueUrl: "https://foo.bar/de"}
+	atClient := &MockClient{queueUrl: "https://foo.bar/at", failBody: `{"tenant":"at"}`}
+	de, at := newRoutedPublisher(t, deClient), newRoutedPublisher(t, atClient)
+
+	router := NewRoutingPublisher().
+		Route(MatchAttribute("Tenant", "de"), de).
+		Route(MatchAttribute("Tenant", "at"), at)
+
+	messages := []interface{}{tenantMessage{Tenant: "de"}, tenantMessage{Tenant: "at"}, tenantMessage{Tenant: "de"}}
+	results, err := router.PublishBatch(context.Background(), messages)
+
+	var partial PartialError
+	require.ErrorAs(t, err, &partial)
+	require.Len(t, partial.Failures, 1)
+	assert.Equal(t, 1, partial.Failures[0].Index)
+	assert.Equal(t, tenantMessage{Tenant: "at"}, partial.Failures[0].Message)
+	assert.Equal(t, "1", aws.ToString(partial.Errors[0].Id))
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "https://foo.bar/de", results[0].QueueURL)
+	require.Len(t, results[0].Results, 2)
+	assert.Equal(t, 0, results[0].Results[0].Index)
+	assert.Equal(t, 2, results[0].Results[1].Index)
+	assert.Equal(t, "https://foo.bar/at", results[1].QueueURL)
+	assert.Empty(t, results[1].Results)
+	assert.Error(t, results[1].Err)
+}
+
+func TestRoutingPublisher_PublishWithoutRoute(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	router := NewRoutingPublisher().Route(MatchType("price"), newRoutedPublisher(t, client))
+
+	_, err := router.Publish(context.Background(), "foo")
+	assert.True(t, errors.Is(err, ErrNoRoute))
+
+	router.Fallback = newRoutedPublisher(t, client)
+	results, err := router.Publish(context.Background(), "foo")
+	require.Nil(t, err)
+	assert.Len(t, results, 1)
+}
@@ -0,0 +1,19 @@
+package publish
+
+import (
+	"math"
+	"time"
+)
+
+// MaxDelaySeconds is the longest delay SQS supports
+const MaxDelaySeconds = 900
+
+// delaySeconds returns the delay till the time capped to MaxDelaySeconds
+func delaySeconds(at time.Time) int32 {
+	seconds := math.Ceil(time.Until(at).Seconds())
+	if seconds <= 0 {
+		return 0
+	}
+
+	return int32(math.Min(seconds, MaxDelaySeconds))
+}
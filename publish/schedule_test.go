@@ -0,0 +1,42 @@
+package publish
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisher_PublishWithDeliverAt(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+
+	at := time.Now().Add(2 * time.Minute)
+	_, err = publisher.Publish(context.Background(), "foo", WithDeliverAt(at))
+	require.Nil(t, err)
+	_, err = publisher.Publish(context.Background(), "foo", WithDeliverAfter(24*time.Hour))
+	require.Nil(t, err)
+
+	require.Len(t, client.sent, 2)
+	assert.Equal(t, at.UTC().Format(time.RFC3339Nano), aws.ToString(client.sent[0].MessageAttributes[utils.DeliverAtAttribute].StringValue))
+	assert.InDelta(t, 120, client.sent[0].DelaySeconds, 1)
+	assert.Equal(t, int32(MaxDelaySeconds), client.sent[1].DelaySeconds)
+}
+
+func TestPublisher_PublishWithDeliverAtToFIFO(t *testing.T) {
+	client := &MockClient{queueUrl: "https://foo.bar/baz.fifo"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz.fifo", IsFIFO: true}, client)
+	require.Nil(t, err)
+
+	_, err = publisher.Publish(context.Background(), "foo", WithMessageGroupID("bar"), WithDeliverAfter(time.Hour))
+	require.Nil(t, err)
+
+	require.Len(t, client.sent, 1)
+	assert.Contains(t, client.sent[0].MessageAttributes, utils.DeliverAtAttribute)
+	assert.Equal(t, int32(0), client.sent[0].DelaySeconds)
+}
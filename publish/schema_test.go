@@ -0,0 +1,49 @@
+package publish
+
+import (
+	"context"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/codec"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisher_PublishRejectsInvalidMessage(t *testing.T) {
+	registry := schema.NewRegistry()
+	require.Nil(t, registry.Register("-", `{"type":"object","required":["foo"]}`))
+
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+	publisher.Schemas = registry
+
+	_, err = publisher.PublishBatch(context.Background(), []interface{}{map[string]int{"foo": 1}, map[string]int{"bar": 1}})
+	assert.IsType(t, &schema.ValidationError{}, err)
+	assert.Empty(t, client.batches)
+
+	_, err = publisher.Publish(context.Background(), map[string]int{"foo": 1})
+	require.Nil(t, err)
+	assert.Len(t, client.sent, 1)
+}
+
+func TestPublisher_PublishValidatesOnlyJSON(t *testing.T) {
+	registry := schema.NewRegistry()
+	require.Nil(t, registry.Register("-", `{"type":"object","required":["foo"]}`))
+
+	client := &MockClient{queueUrl: "https://foo.bar/baz"}
+	publisher, err := NewPublisher(PublisherConfig{QueueName: "baz"}, client)
+	require.Nil(t, err)
+	publisher.Schemas = registry
+
+	_, err = publisher.Publish(context.Background(), map[string]int{"foo": 1}, WithCodec(codec.MessagePack))
+	var validationErr *schema.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Contains(t, validationErr.Violations[0], "application/msgpack")
+	assert.Empty(t, client.sent)
+
+	_, err = publisher.Publish(context.Background(), map[string]int{"foo": 1}, WithCodec(codec.MessagePack), WithMessageType("bar"))
+	require.Nil(t, err)
+	assert.Len(t, client.sent, 1)
+}
@@ -2,32 +2,94 @@ package queue
 
 import (
 	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsHttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"time"
 )
 
 const waitOnError = 5 + time.Second
 
 type optionFns []func(o *sqs.Options)
 
+// NewSQSClient creates a client with the settings of the config, settings left empty are resolved by the default
+// chain of the SDK, e.g. the region and credentials from the environment or the shared config files
 func NewSQSClient(config ClientConfig) (*sqs.Client, error) {
-	cfg, err := awsConfig.LoadDefaultConfig(context.TODO())
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 
+	cfg, err := awsConfig.LoadDefaultConfig(context.TODO(), loadOptions(config)...)
 	if err != nil {
 		return nil, err
 	}
 
-	optFns := optionFns{}
+	optFns := optionFns{
+		func(o *sqs.Options) {
+			o.EndpointOptions.DisableHTTPS = config.DisableHTTPS
+		},
+	}
 
 	if config.Endpoint != nil {
 		optFns = append(optFns, func(o *sqs.Options) {
-			o.BaseEndpoint = config.Endpoint
-			o.EndpointOptions.DisableHTTPS = true
+			o.BaseEndpoint = aws.String(config.endpoint())
 		})
 	}
 
-	client := sqs.NewFromConfig(cfg, optFns...)
+	// set on the client only, the CA bundle of the shared config cannot be applied to any HTTP client
+	if config.HTTPClient != nil {
+		optFns = append(optFns, func(o *sqs.Options) {
+			o.HTTPClient = config.HTTPClient
+		})
+	}
+
+	return sqs.NewFromConfig(cfg, optFns...), nil
+}
+
+func loadOptions(config ClientConfig) []func(*awsConfig.LoadOptions) error {
+	options := []func(*awsConfig.LoadOptions) error{}
+
+	if config.AWSRegion != "" {
+		options = append(options, awsConfig.WithRegion(config.AWSRegion))
+	}
+	if config.AWSAccessKey != "" {
+		provider := credentials.NewStaticCredentialsProvider(config.AWSAccessKey, config.AWSSecretKey, config.AWSSessionToken)
+		options = append(options, awsConfig.WithCredentialsProvider(provider))
+	}
+	if config.Retryer != nil {
+		options = append(options, awsConfig.WithRetryer(config.Retryer))
+	}
+	if config.MaxAttempts > 0 {
+		options = append(options, awsConfig.WithRetryMaxAttempts(config.MaxAttempts))
+	}
+
+	if config.HTTPClient == nil && (config.TLSConfig != nil || config.Timeout > 0 || config.ConnectTimeout > 0) {
+		options = append(options, awsConfig.WithHTTPClient(newHTTPClient(config)))
+	}
 
-	return client, err
+	return options
+}
+
+// newHTTPClient returns the default HTTP client of the SDK with the TLS config and timeouts applied
+func newHTTPClient(config ClientConfig) *awsHttp.BuildableClient {
+	return awsHttp.NewBuildableClient().
+		WithTimeout(config.Timeout).
+		WithDialerOptions(func(dialer *net.Dialer) {
+			if config.ConnectTimeout > 0 {
+				dialer.Timeout = config.ConnectTimeout
+			}
+		}).
+		WithTransportOptions(func(transport *http.Transport) {
+			if config.TLSConfig != nil {
+				transport.TLSClientConfig = config.TLSConfig
+			}
+			if config.ConnectTimeout > 0 {
+				transport.TLSHandshakeTimeout = config.ConnectTimeout
+			}
+		})
 }
@@ -1,10 +1,18 @@
 package queue
 
 import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awsHttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"testing"
 )
 
 func TestNewSQSClientOK(t *testing.T) {
@@ -15,3 +23,58 @@ func TestNewSQSClientOK(t *testing.T) {
 	assert.Nil(t, err)
 	assert.IsType(t, &sqs.Client{}, client)
 }
+
+func TestNewSQSClient_Config(t *testing.T) {
+	client, err := NewSQSClient(ClientConfig{
+		Endpoint:        aws.String("localhost:9324"),
+		AWSAccessKey:    "foo",
+		AWSSecretKey:    "bar",
+		AWSSessionToken: "baz",
+		AWSRegion:       "eu-west-1",
+		DisableHTTPS:    true,
+		MaxAttempts:     7,
+		Timeout:         time.Minute,
+		TLSConfig:       &tls.Config{MinVersion: tls.VersionTLS13},
+	})
+	require.Nil(t, err)
+
+	options := client.Options()
+	assert.Equal(t, "eu-west-1", options.Region)
+	assert.Equal(t, "http://localhost:9324", aws.ToString(options.BaseEndpoint))
+	assert.True(t, options.EndpointOptions.DisableHTTPS)
+	assert.Equal(t, 7, options.Retryer.MaxAttempts())
+
+	credentials, err := options.Credentials.Retrieve(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, "foo", credentials.AccessKeyID)
+	assert.Equal(t, "bar", credentials.SecretAccessKey)
+	assert.Equal(t, "baz", credentials.SessionToken)
+
+	httpClient, ok := options.HTTPClient.(*awsHttp.BuildableClient)
+	require.True(t, ok)
+	assert.Equal(t, time.Minute, httpClient.GetTimeout())
+	assert.Equal(t, uint16(tls.VersionTLS13), httpClient.GetTransport().TLSClientConfig.MinVersion)
+}
+
+func TestNewSQSClient_EndpointWithoutScheme(t *testing.T) {
+	client, err := NewSQSClient(ClientConfig{Endpoint: aws.String("localhost:9324"), AWSRegion: "eu-west-1"})
+	require.Nil(t, err)
+
+	assert.Equal(t, "http://localhost:9324", aws.ToString(client.Options().BaseEndpoint))
+}
+
+func TestNewSQSClient_CustomRetryerAndHTTPClient(t *testing.T) {
+	httpClient := &http.Client{Timeout: time.Minute}
+	client, err := NewSQSClient(ClientConfig{
+		Endpoint:   aws.String("https://sqs.example.com"),
+		Retryer:    func() aws.Retryer { return retry.AddWithMaxAttempts(retry.NewStandard(), 2) },
+		HTTPClient: httpClient,
+	})
+	require.Nil(t, err)
+
+	options := client.Options()
+	assert.Equal(t, "https://sqs.example.com", aws.ToString(options.BaseEndpoint))
+	assert.False(t, options.EndpointOptions.DisableHTTPS)
+	assert.Equal(t, 2, options.Retryer.MaxAttempts())
+	assert.Same(t, httpClient, options.HTTPClient)
+}
@@ -1,15 +1,121 @@
 package queue
 
+import (
+	"crypto/tls"
+	"errors"
+	"net/url"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
 type ClientConfig struct {
-	Endpoint     *string `envconfig:"AWS_SQS_URL" required:"false"`
-	AWSAccessKey string  `envconfig:"AWS_ACCESS_KEY" required:"false"`
-	AWSSecretKey string  `envconfig:"AWS_SECRET_KEY" required:"false"`
-	AWSRegion    string  `envconfig:"AWS_REGION" default:"eu-central-1" required:"true"`
+	Endpoint        *string `envconfig:"AWS_SQS_URL" required:"false"`
+	AWSAccessKey    string  `envconfig:"AWS_ACCESS_KEY" required:"false"`
+	AWSSecretKey    string  `envconfig:"AWS_SECRET_KEY" required:"false"`
+	AWSSessionToken string  `envconfig:"AWS_SESSION_TOKEN" required:"false"`
+	AWSRegion       string  `envconfig:"AWS_REGION" default:"eu-central-1" required:"true"`
+	// DisableHTTPS sends requests over plain HTTP, e.g. to a local SQS. An Endpoint without scheme always gets http://.
+	DisableHTTPS bool `envconfig:"AWS_SQS_DISABLE_HTTPS"`
+	// MaxAttempts of requests including retries, the default of the SDK if 0
+	MaxAttempts int `envconfig:"AWS_SQS_MAX_ATTEMPTS"`
+	// Timeout of requests, it has to exceed the wait time of long polling
+	Timeout        time.Duration `envconfig:"AWS_SQS_TIMEOUT"`
+	ConnectTimeout time.Duration `envconfig:"AWS_SQS_CONNECT_TIMEOUT"`
+	// TLSConfig configures TLS of the default HTTP client, e.g. custom root CAs
+	TLSConfig *tls.Config `ignored:"true"`
+	// Retryer replaces the retryer of the SDK
+	Retryer func() aws.Retryer `ignored:"true"`
+	// HTTPClient replaces the HTTP client of the SDK, TLSConfig, the timeouts and AWS_CA_BUNDLE are not applied to it
+	HTTPClient aws.HTTPClient `ignored:"true"`
+}
+
+// Validate reports inconsistent settings, an empty region is resolved by the SDK
+func (c ClientConfig) Validate() error {
+	errs := []error{}
+
+	if (c.AWSAccessKey == "") != (c.AWSSecretKey == "") {
+		errs = append(errs, utils.InvalidConfig("AWSAccessKey and AWSSecretKey have to be set together"))
+	}
+	if c.AWSSessionToken != "" && c.AWSAccessKey == "" {
+		errs = append(errs, utils.InvalidConfig("AWSSessionToken requires AWSAccessKey and AWSSecretKey"))
+	}
+	if c.Endpoint != nil {
+		if u, err := url.Parse(c.endpoint()); err != nil || u.Host == "" {
+			errs = append(errs, utils.InvalidConfig("Endpoint %s is not a valid URL", *c.Endpoint))
+		}
+	}
+	if c.MaxAttempts < 0 {
+		errs = append(errs, utils.InvalidConfig("MaxAttempts must not be negative"))
+	}
+	if c.Timeout < 0 || c.ConnectTimeout < 0 {
+		errs = append(errs, utils.InvalidConfig("timeouts must not be negative"))
+	}
+	if c.Timeout > 0 && c.Timeout <= maxWaitTimeSeconds*time.Second {
+		errs = append(errs, utils.InvalidConfig("Timeout has to exceed the long polling wait time of up to %ds", maxWaitTimeSeconds))
+	}
+	if c.HTTPClient != nil && (c.TLSConfig != nil || c.Timeout > 0 || c.ConnectTimeout > 0) {
+		errs = append(errs, utils.InvalidConfig("TLSConfig and timeouts are not applied to a custom HTTPClient"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// endpoint returns the Endpoint, http:// is added if it has no scheme as local setups like localhost:9324 rely on it
+func (c ClientConfig) endpoint() string {
+	endpoint := aws.ToString(c.Endpoint)
+	if u, err := url.Parse(endpoint); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return endpoint
+	}
+
+	return "http://" + endpoint
+}
+
+// LoadClientConfig loads the config from the environment, see utils.LoadConfig
+func LoadClientConfig(prefix string) (ClientConfig, error) {
+	config := ClientConfig{}
+	err := utils.LoadConfig(prefix, &config)
+
+	return config, err
 }
 
 type ConsumerConfig struct {
+	// QueueName is the name, URL or ARN of the queue
 	QueueName           string `envconfig:"AWS_SQS_QUEUE_NAME" required:"true"`
 	MaxNumberOfMessages int32  `envconfig:"AWS_SQS_QUEUE_MAX_MESSAGES_PER_BATCH" default:"10"` // approximate, will round up to 10
 	WaitTimeSeconds     int32  `envconfig:"AWS_SQS_QUEUE_WAIT_TIME" default:"5"`
 	VisibilityTimeout   int32  `envconfig:"AWS_SQS_QUEUE_VISIBILITY_TIMEOUT" default:"60"`
+	// QueueOwnerAWSAccountID is the account of a queue of another account given by name
+	QueueOwnerAWSAccountID string `envconfig:"AWS_SQS_QUEUE_OWNER_ACCOUNT_ID"`
+	// LazyQueueURL resolves the queue URL on the first receive instead of in NewConsumer, retrying failures
+	LazyQueueURL bool `envconfig:"AWS_SQS_LAZY_QUEUE_URL"`
+}
+
+// Validate reports settings SQS would reject
+func (c ConsumerConfig) Validate() error {
+	errs := []error{}
+
+	if c.QueueName == "" {
+		errs = append(errs, utils.InvalidConfig("QueueName is required"))
+	}
+	if c.MaxNumberOfMessages < 1 {
+		errs = append(errs, utils.InvalidConfig("MaxNumberOfMessages has to be at least 1"))
+	}
+	if c.WaitTimeSeconds < 0 || c.WaitTimeSeconds > maxWaitTimeSeconds {
+		errs = append(errs, utils.InvalidConfig("WaitTimeSeconds has to be between 0 and %d", maxWaitTimeSeconds))
+	}
+	if c.VisibilityTimeout < 0 || c.VisibilityTimeout > maxVisibilityTimeout {
+		errs = append(errs, utils.InvalidConfig("VisibilityTimeout has to be between 0 and %d", maxVisibilityTimeout))
+	}
+
+	return errors.Join(errs...)
+}
+
+// LoadConsumerConfig loads the config from the environment, see utils.LoadConfig
+func LoadConsumerConfig(prefix string) (ConsumerConfig, error) {
+	config := ConsumerConfig{}
+	err := utils.LoadConfig(prefix, &config)
+
+	return config, err
 }
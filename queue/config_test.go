@@ -0,0 +1,59 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsHttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientConfig_Validate(t *testing.T) {
+	assert.Nil(t, ClientConfig{}.Validate())
+	assert.ErrorIs(t, ClientConfig{AWSAccessKey: "foo"}.Validate(), utils.ErrInvalidConfig)
+	assert.ErrorIs(t, ClientConfig{AWSSessionToken: "foo"}.Validate(), utils.ErrInvalidConfig)
+	assert.ErrorIs(t, ClientConfig{Endpoint: aws.String("http://")}.Validate(), utils.ErrInvalidConfig)
+	assert.ErrorIs(t, ClientConfig{Timeout: 10 * time.Second}.Validate(), utils.ErrInvalidConfig)
+	assert.ErrorIs(t, ClientConfig{HTTPClient: awsHttp.NewBuildableClient(), ConnectTimeout: time.Second}.Validate(), utils.ErrInvalidConfig)
+
+	_, err := NewSQSClient(ClientConfig{MaxAttempts: -1})
+	assert.ErrorIs(t, err, utils.ErrInvalidConfig)
+}
+
+func TestLoadClientConfig(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY", "foo")
+	t.Setenv("AWS_SECRET_KEY", "bar")
+	t.Setenv("AWS_SQS_URL", "http://localhost:9324")
+	t.Setenv("AWS_SQS_TIMEOUT", "30s")
+
+	config, err := LoadClientConfig("")
+	require.Nil(t, err)
+	assert.Equal(t, "foo", config.AWSAccessKey)
+	assert.Equal(t, "bar", config.AWSSecretKey)
+	assert.Equal(t, "eu-central-1", config.AWSRegion)
+	assert.Equal(t, "http://localhost:9324", aws.ToString(config.Endpoint))
+	assert.Equal(t, 30*time.Second, config.Timeout)
+
+	t.Setenv("AWS_SECRET_KEY", "")
+	_, err = LoadClientConfig("")
+	assert.ErrorIs(t, err, utils.ErrInvalidConfig)
+}
+
+func TestLoadConsumerConfig(t *testing.T) {
+	t.Setenv("ORDERS_AWS_SQS_QUEUE_NAME", "orders")
+	t.Setenv("ORDERS_AWS_SQS_QUEUE_VISIBILITY_TIMEOUT", "120")
+
+	config, err := LoadConsumerConfig("ORDERS")
+	require.Nil(t, err)
+	assert.Equal(t, ConsumerConfig{QueueName: "orders", MaxNumberOfMessages: 10, WaitTimeSeconds: 5, VisibilityTimeout: 120}, config)
+
+	_, err = LoadConsumerConfig("PRICES")
+	assert.ErrorIs(t, err, utils.ErrInvalidConfig)
+
+	t.Setenv("ORDERS_AWS_SQS_QUEUE_WAIT_TIME", "21")
+	_, err = LoadConsumerConfig("ORDERS")
+	assert.ErrorIs(t, err, utils.ErrInvalidConfig)
+}
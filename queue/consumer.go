@@ -29,24 +29,28 @@ type SQSReceiver interface {
 
 // Consumer struct
 type Consumer struct {
-	queueURL            string
+	queueURL            *utils.QueueURL
 	maxNumberOfMessages int32
 	waitTimeSeconds     int32
 	visibilityTimeout   int32
 	waitOnError         time.Duration
 
-	handler BatchHandler
-	client  SQSReceiver
+	handler   BatchHandler
+	client    SQSReceiver
+	observers observers
+	decoders  []MessageDecoder
 }
 
 func NewConsumer(config ConsumerConfig, client SQSClient, handler BatchHandler) (*Consumer, error) {
-	queueUrl, err := utils.GetQueueURL(client, config.QueueName)
-	if err != nil {
-		return nil, err
+	queueURL := utils.NewQueueURL(client, config.QueueName, config.QueueOwnerAWSAccountID)
+	if !config.LazyQueueURL {
+		if _, err := queueURL.Get(context.Background()); err != nil {
+			return nil, err
+		}
 	}
 
 	return &Consumer{
-		queueURL:            *queueUrl,
+		queueURL:            queueURL,
 		maxNumberOfMessages: config.MaxNumberOfMessages,
 		waitTimeSeconds:     config.WaitTimeSeconds,
 		visibilityTimeout:   config.VisibilityTimeout,
@@ -57,12 +61,36 @@ func NewConsumer(config ConsumerConfig, client SQSClient, handler BatchHandler)
 	}, nil
 }
 
+// QueueURL returns the URL of the consumed queue, with LazyQueueURL it is empty till the first receive resolved it
+func (c *Consumer) QueueURL() string {
+	if c.queueURL == nil {
+		return ""
+	}
+
+	return c.queueURL.String()
+}
+
+// ResolveQueueURL returns the URL of the consumed queue, resolving it if LazyQueueURL deferred it
+func (c *Consumer) ResolveQueueURL(ctx context.Context) (string, error) {
+	if c.queueURL == nil {
+		return "", nil
+	}
+
+	return c.queueURL.Get(ctx)
+}
+
+// AddObserver registers observers that get notified about the stages of each batch cycle
+func (c *Consumer) AddObserver(o ...Observer) {
+	c.observers = append(c.observers, o...)
+}
+
 // Start starts the polling and will continue polling till the application is forcibly stopped
 func (c *Consumer) Start(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
 			logrus.Debug("consumer: Stopping polling because a context kill signal was sent")
+			c.observers.OnShutdown(ctx)
 			return
 		default:
 			c.runBatch(ctx)
@@ -71,15 +99,43 @@ func (c *Consumer) Start(ctx context.Context) {
 }
 
 func (c *Consumer) runBatch(ctx context.Context) {
+	c.observers.OnPollStart(ctx)
+
+	if c.queueURL != nil {
+		if _, err := c.queueURL.Get(ctx); err != nil {
+			c.observers.OnError(ctx, err)
+			c.wait(ctx)
+			return
+		}
+	}
+
+	receivedAt := time.Now()
 	messages := c.pullMessages(ctx)
 	numMessages := len(messages)
 	if numMessages > 0 {
 		logrus.Infof("consumer: Received %d messages", numMessages)
+		c.observers.OnReceived(ctx, messages)
+	}
+
+	messages, discarded := c.decodeMessages(withReceiveTime(ctx, receivedAt), messages)
+	if len(messages) > 0 {
 		c.consumeMessages(ctx, messages)
+	}
+
+	messages = append(messages, discarded...)
+	if len(messages) > 0 {
 		c.dropMessages(ctx, messages)
 	}
 }
 
+// wait pauses polling after errors for waitOnError or till the context is done
+func (c *Consumer) wait(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(c.waitOnError):
+	}
+}
+
 func (c *Consumer) pullMessages(ctx context.Context) []awsTypes.Message {
 	requests := c.generateReceiveRequests()
 
@@ -96,7 +152,9 @@ func (c *Consumer) pullMessages(ctx context.Context) []awsTypes.Message {
 
 		go func(r *sqs.ReceiveMessageInput) {
 			result, err := c.client.ReceiveMessage(ctx, r)
-			if err == nil && len(result.Messages) > 0 {
+			if err != nil {
+				c.observers.OnError(ctx, err)
+			} else if len(result.Messages) > 0 {
 				mx.Lock()
 				defer mx.Unlock()
 				messages = append(messages, result.Messages...)
@@ -120,10 +178,17 @@ func (c *Consumer) consumeMessages(ctx context.Context, messages []awsTypes.Mess
 		return
 	}
 
+	start := time.Now()
 	err := c.handler.Handle(ctx, messages)
 	if err != nil {
 		logrus.Error(err)
 	}
+
+	c.observers.OnBatchHandled(ctx, BatchOutcome{
+		Messages: messages,
+		Duration: time.Since(start),
+		Err:      err,
+	})
 }
 
 func (c *Consumer) dropMessages(ctx context.Context, messages []awsTypes.Message) {
@@ -143,7 +208,12 @@ func (c *Consumer) dropMessages(ctx context.Context, messages []awsTypes.Message
 
 			go func(b []awsTypes.Message) {
 				req := c.createBulkDeleteRequest(b)
-				logDeleteResult(c.client.DeleteMessageBatch(ctx, req))
+				result, err := c.client.DeleteMessageBatch(ctx, req)
+				logDeleteResult(result, err)
+				c.notifyDeleted(ctx, result, err)
+				if err == nil && result != nil {
+					c.releaseMessages(ctx, b, deletedIds(result))
+				}
 
 				<-semaphore
 				wg.Done()
@@ -156,6 +226,27 @@ func (c *Consumer) dropMessages(ctx context.Context, messages []awsTypes.Message
 	wg.Wait()
 }
 
+func (c *Consumer) notifyDeleted(ctx context.Context, result *sqs.DeleteMessageBatchOutput, err error) {
+	if err != nil {
+		c.observers.OnError(ctx, err)
+		return
+	}
+	if result == nil {
+		return
+	}
+
+	c.observers.OnDeleted(ctx, DeleteResult{Deleted: deletedIds(result), Failed: result.Failed})
+}
+
+func deletedIds(result *sqs.DeleteMessageBatchOutput) []string {
+	deleted := []string{}
+	for _, success := range result.Successful {
+		deleted = append(deleted, aws.ToString(success.Id))
+	}
+
+	return deleted
+}
+
 func logDeleteResult(result *sqs.DeleteMessageBatchOutput, err error) {
 	if err != nil {
 		logrus.Error(err)
@@ -191,7 +282,7 @@ func (c *Consumer) generateReceiveRequests() []*sqs.ReceiveMessageInput {
 func (c *Consumer) createReceiveRequest(maxMessagesPerRequest int32) *sqs.ReceiveMessageInput {
 
 	input := &sqs.ReceiveMessageInput{
-		QueueUrl:              aws.String(c.queueURL),
+		QueueUrl:              aws.String(c.QueueURL()),
 		MaxNumberOfMessages:   maxMessagesPerRequest,
 		VisibilityTimeout:     c.visibilityTimeout,
 		MessageAttributeNames: []string{".*"},
@@ -215,7 +306,7 @@ func (c *Consumer) createBulkDeleteRequest(messages []awsTypes.Message) *sqs.Del
 	}
 
 	return &sqs.DeleteMessageBatchInput{
-		QueueUrl: aws.String(c.queueURL),
+		QueueUrl: aws.String(c.QueueURL()),
 		Entries:  entries,
 	}
 }
@@ -129,3 +129,21 @@ func TestConsumer_ConsumeHandle(t *testing.T) {
 	assert.LessOrEqual(t, client.deleteBatchSizes[0], 10)
 	assert.LessOrEqual(t, client.deleteBatchSizes[1], 10)
 }
+
+func TestConsumer_LazyQueueURL(t *testing.T) {
+	client := &MockClient{queueUrlErr: errors.New("unavailable"), cancel: func() {}}
+	consumer, err := NewConsumer(ConsumerConfig{QueueName: "baz", MaxNumberOfMessages: 10, LazyQueueURL: true}, client, &MockBatchHandler{})
+	require.Nil(t, err)
+	consumer.waitOnError = 0
+	assert.Empty(t, consumer.QueueURL())
+
+	observer := &MockObserver{}
+	consumer.AddObserver(observer)
+	consumer.runBatch(context.Background())
+	assert.Empty(t, consumer.QueueURL())
+	assert.Len(t, observer.errors, 1)
+
+	client.queueUrl, client.queueUrlErr = "https://foo.bar/baz", nil
+	consumer.runBatch(context.Background())
+	assert.Equal(t, "https://foo.bar/baz", consumer.QueueURL())
+}
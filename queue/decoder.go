@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrDiscard is wrapped by decoder errors to have the consumer delete the message instead of retaining it
+var ErrDiscard = errors.New("message discarded")
+
+// MessageDecoder transforms a received message in place before it is passed to the handler.
+// A message failing to decode is not handled and becomes visible again after the visibility timeout,
+// unless the error wraps ErrDiscard in which case it gets deleted.
+type MessageDecoder interface {
+	Decode(ctx context.Context, msg *awsTypes.Message) error
+}
+
+// MessageReleaser is implemented by decoders that need to clean up once a message got deleted from the queue
+type MessageReleaser interface {
+	Release(ctx context.Context, msg awsTypes.Message) error
+}
+
+// AddDecoder registers decoders, they are applied in registration order which has to be the reverse of the encoding order on publishing
+func (c *Consumer) AddDecoder(d ...MessageDecoder) {
+	c.decoders = append(c.decoders, d...)
+}
+
+// decodeMessages returns the messages that passed all decoders and the failed ones to be deleted anyway
+func (c *Consumer) decodeMessages(ctx context.Context, messages []awsTypes.Message) (decoded []awsTypes.Message, discarded []awsTypes.Message) {
+	if len(c.decoders) == 0 {
+		return messages, nil
+	}
+
+	for _, m := range messages {
+		if err := c.decodeMessage(ctx, &m); err != nil {
+			if errors.Is(err, ErrNotDue) {
+				logrus.Debugf("consumer: deferring message %s: %s", aws.ToString(m.MessageId), err)
+			} else {
+				logrus.Errorf("consumer: decoding message %s failed: %s", aws.ToString(m.MessageId), err)
+				c.observers.OnError(ctx, err)
+			}
+
+			if errors.Is(err, ErrDiscard) {
+				discarded = append(discarded, m)
+			}
+			continue
+		}
+
+		decoded = append(decoded, m)
+	}
+
+	return decoded, discarded
+}
+
+func (c *Consumer) decodeMessage(ctx context.Context, msg *awsTypes.Message) error {
+	for _, d := range c.decoders {
+		if err := d.Decode(ctx, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Consumer) releaseMessages(ctx context.Context, messages []awsTypes.Message, deleted []string) {
+	if len(c.decoders) == 0 {
+		return
+	}
+
+	ids := map[string]bool{}
+	for _, id := range deleted {
+		ids[id] = true
+	}
+
+	for _, m := range messages {
+		if !ids[aws.ToString(m.MessageId)] {
+			continue
+		}
+
+		for _, d := range c.decoders {
+			releaser, ok := d.(MessageReleaser)
+			if !ok {
+				continue
+			}
+
+			if err := releaser.Release(ctx, m); err != nil {
+				logrus.Errorf("consumer: releasing message %s failed: %s", aws.ToString(m.MessageId), err)
+				c.observers.OnError(ctx, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package queue
+
+import (
+	"context"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/compression"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Decompressor restores bodies compressed by the publisher as marked by the Content-Encoding attribute
+type Decompressor struct{}
+
+func NewDecompressor() *Decompressor {
+	return &Decompressor{}
+}
+
+func (d *Decompressor) Decode(_ context.Context, msg *awsTypes.Message) error {
+	attr, ok := msg.MessageAttributes[compression.EncodingAttribute]
+	if !ok || attr.StringValue == nil {
+		return nil
+	}
+
+	codec, err := compression.Lookup(*attr.StringValue)
+	if err != nil {
+		return err
+	}
+
+	body, err := compression.Decode(codec, aws.ToString(msg.Body))
+	if err != nil {
+		return err
+	}
+
+	msg.Body = aws.String(body)
+
+	return nil
+}
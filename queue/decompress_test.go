@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/compression"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecompressor_Decode(t *testing.T) {
+	body, err := compression.Encode(compression.Zstd, `{"foo":"bar"}`)
+	require.Nil(t, err)
+
+	msg := types.Message{
+		Body: aws.String(body),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			compression.EncodingAttribute: {DataType: aws.String("String"), StringValue: aws.String("zstd")},
+		},
+	}
+	plain := types.Message{Body: aws.String("foo")}
+
+	decompressor := NewDecompressor()
+	require.Nil(t, decompressor.Decode(context.Background(), &msg))
+	require.Nil(t, decompressor.Decode(context.Background(), &plain))
+
+	assert.Equal(t, `{"foo":"bar"}`, aws.ToString(msg.Body))
+	assert.Equal(t, "foo", aws.ToString(plain.Body))
+}
+
+func TestDecompressor_DecodeUnknownEncoding(t *testing.T) {
+	msg := types.Message{
+		Body: aws.String("foo"),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			compression.EncodingAttribute: {DataType: aws.String("String"), StringValue: aws.String("br")},
+		},
+	}
+
+	assert.Error(t, NewDecompressor().Decode(context.Background(), &msg))
+}
@@ -0,0 +1,59 @@
+package queue
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/encryption"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// ErrNotEncrypted is returned by a Decrypter requiring encryption for messages without encryption attributes
+var ErrNotEncrypted = errors.New("message not encrypted")
+
+// Decrypter decrypts bodies encrypted by the publisher, messages without encryption attributes are passed unchanged
+// unless Required is set
+type Decrypter struct {
+	// Required rejects messages which are not encrypted
+	Required bool
+	provider encryption.KeyProvider
+}
+
+func NewDecrypter(provider encryption.KeyProvider) *Decrypter {
+	return &Decrypter{
+		provider: provider,
+	}
+}
+
+func (d *Decrypter) Decode(ctx context.Context, msg *awsTypes.Message) error {
+	algorithm, ok := msg.MessageAttributes[encryption.AlgorithmAttribute]
+	if !ok && d.Required {
+		return ErrNotEncrypted
+	}
+	if !ok {
+		return nil
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(aws.ToString(msg.Body))
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := encryption.Open(ctx, d.provider, encryption.Envelope{
+		Algorithm:    aws.ToString(algorithm.StringValue),
+		KeyID:        aws.ToString(msg.MessageAttributes[encryption.KeyIDAttribute].StringValue),
+		EncryptedKey: msg.MessageAttributes[encryption.DataKeyAttribute].BinaryValue,
+		Ciphertext:   ciphertext,
+		// the message type selects the handler, it must not be changed without the key
+		AssociatedData: []byte(MessageType(*msg)),
+	})
+	if err != nil {
+		return err
+	}
+
+	msg.Body = aws.String(string(plaintext))
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/encryption"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecrypter_Decode(t *testing.T) {
+	ctx := context.Background()
+	provider, err := encryption.NewStaticKeyProvider("foo", map[string][]byte{"foo": bytes.Repeat([]byte{1}, 32)})
+	require.Nil(t, err)
+
+	envelope, err := encryption.Seal(ctx, provider, []byte(`{"foo":"bar"}`), []byte("order"))
+	require.Nil(t, err)
+
+	msg := types.Message{
+		Body: aws.String(base64.StdEncoding.EncodeToString(envelope.Ciphertext)),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			encryption.AlgorithmAttribute: {DataType: aws.String("String"), StringValue: aws.String(envelope.Algorithm)},
+			encryption.KeyIDAttribute:     {DataType: aws.String("String"), StringValue: aws.String(envelope.KeyID)},
+			encryption.DataKeyAttribute:   {DataType: aws.String("Binary"), BinaryValue: envelope.EncryptedKey},
+			utils.MessageTypeAttribute:    {DataType: aws.String("String"), StringValue: aws.String("order")},
+		},
+	}
+	plain := types.Message{Body: aws.String("foo")}
+
+	decrypter := NewDecrypter(provider)
+	require.Nil(t, decrypter.Decode(ctx, &msg))
+	require.Nil(t, decrypter.Decode(ctx, &plain))
+
+	assert.Equal(t, `{"foo":"bar"}`, aws.ToString(msg.Body))
+	assert.Equal(t, "foo", aws.ToString(plain.Body))
+
+	msg.MessageAttributes[encryption.KeyIDAttribute] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String("bar")}
+	msg.Body = aws.String(base64.StdEncoding.EncodeToString(envelope.Ciphertext))
+	assert.Error(t, decrypter.Decode(ctx, &msg))
+}
+
+func TestDecrypter_DecodeRejectsTampering(t *testing.T) {
+	ctx := context.Background()
+	provider, err := encryption.NewStaticKeyProvider("foo", map[string][]byte{"foo": bytes.Repeat([]byte{1}, 32)})
+	require.Nil(t, err)
+
+	envelope, err := encryption.Seal(ctx, provider, []byte(`{"foo":"bar"}`), []byte("order"))
+	require.Nil(t, err)
+
+	message := func(algorithm string, messageType string) *types.Message {
+		return &types.Message{
+			Body: aws.String(base64.StdEncoding.EncodeToString(envelope.Ciphertext)),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				encryption.AlgorithmAttribute: {DataType: aws.String("String"), StringValue: aws.String(algorithm)},
+				encryption.KeyIDAttribute:     {DataType: aws.String("String"), StringValue: aws.String(envelope.KeyID)},
+				encryption.DataKeyAttribute:   {DataType: aws.String("Binary"), BinaryValue: envelope.EncryptedKey},
+				utils.MessageTypeAttribute:    {DataType: aws.String("String"), StringValue: aws.String(messageType)},
+			},
+		}
+	}
+
+	decrypter := NewDecrypter(provider)
+	assert.Error(t, decrypter.Decode(ctx, message(envelope.Algorithm, "refund")))
+	assert.Error(t, decrypter.Decode(ctx, message("AES-128-CBC", "order")))
+
+	decrypter.Required = true
+	assert.ErrorIs(t, decrypter.Decode(ctx, &types.Message{Body: aws.String("foo")}), ErrNotEncrypted)
+
+	msg := message(envelope.Algorithm, "order")
+	require.Nil(t, decrypter.Decode(ctx, msg))
+	assert.Equal(t, `{"foo":"bar"}`, aws.ToString(msg.Body))
+}
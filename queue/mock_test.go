@@ -19,9 +19,14 @@ type MockClient struct {
 	deletedMessages  []*string
 	deleteBatchSizes []int
 	deleteErr        error
+	receiveErr       error
 }
 
 func (m *MockClient) ReceiveMessage(ctx context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	if m.receiveErr != nil {
+		return nil, m.receiveErr
+	}
+
 	var messages []types.Message
 	if len(m.messages) == 0 {
 		m.cancel()
@@ -38,13 +43,15 @@ func (m *MockClient) DeleteMessageBatch(ctx context.Context, input *sqs.DeleteMe
 	mx.Lock()
 	defer mx.Unlock()
 
+	output := &sqs.DeleteMessageBatchOutput{}
 	for _, entry := range input.Entries {
 		m.deletedMessages = append(m.deletedMessages, entry.Id)
+		output.Successful = append(output.Successful, types.DeleteMessageBatchResultEntry{Id: entry.Id})
 	}
 
 	m.deleteBatchSizes = append(m.deleteBatchSizes, len(input.Entries))
 
-	return &sqs.DeleteMessageBatchOutput{}, m.deleteErr
+	return output, m.deleteErr
 }
 
 func (m *MockClient) GetQueueUrl(context.Context, *sqs.GetQueueUrlInput, ...func(o *sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
@@ -89,3 +96,72 @@ func (t *MockLogHook) Fire(entry *logrus.Entry) error {
 	t.messages = append(t.messages, entry)
 	return nil
 }
+
+type MockObserver struct {
+	events   []string
+	outcomes []BatchOutcome
+	deleted  []DeleteResult
+	errors   []error
+	mx       sync.Mutex
+}
+
+func (o *MockObserver) record(event string) {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+
+	o.events = append(o.events, event)
+}
+
+func (o *MockObserver) OnPollStart(context.Context) {
+	o.record("poll")
+}
+
+func (o *MockObserver) OnReceived(context.Context, []types.Message) {
+	o.record("received")
+}
+
+func (o *MockObserver) OnBatchHandled(_ context.Context, outcome BatchOutcome) {
+	o.record("handled")
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	o.outcomes = append(o.outcomes, outcome)
+}
+
+func (o *MockObserver) OnDeleted(_ context.Context, result DeleteResult) {
+	o.record("deleted")
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	o.deleted = append(o.deleted, result)
+}
+
+func (o *MockObserver) OnError(_ context.Context, err error) {
+	o.record("error")
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	o.errors = append(o.errors, err)
+}
+
+func (o *MockObserver) OnShutdown(context.Context) {
+	o.record("shutdown")
+}
+
+type MockScheduleClient struct {
+	visibilities []*sqs.ChangeMessageVisibilityInput
+	sent         []*sqs.SendMessageInput
+	deleted      []*sqs.DeleteMessageInput
+}
+
+func (m *MockScheduleClient) ChangeMessageVisibility(_ context.Context, input *sqs.ChangeMessageVisibilityInput, _ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	m.visibilities = append(m.visibilities, input)
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+func (m *MockScheduleClient) SendMessage(_ context.Context, input *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	m.sent = append(m.sent, input)
+	return &sqs.SendMessageOutput{MessageId: aws.String("foo")}, nil
+}
+
+func (m *MockScheduleClient) DeleteMessage(_ context.Context, input *sqs.DeleteMessageInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	m.deleted = append(m.deleted, input)
+	return &sqs.DeleteMessageOutput{}, nil
+}
@@ -0,0 +1,81 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Observer gets notified at the stages of a consumer batch cycle.
+// Callbacks may be invoked from several goroutines at once, implementations need to be safe for concurrent use.
+type Observer interface {
+	OnPollStart(ctx context.Context)
+	OnReceived(ctx context.Context, messages []awsTypes.Message)
+	OnBatchHandled(ctx context.Context, outcome BatchOutcome)
+	OnDeleted(ctx context.Context, result DeleteResult)
+	OnError(ctx context.Context, err error)
+	OnShutdown(ctx context.Context)
+}
+
+// BatchOutcome describes the result of passing a batch to the handler
+type BatchOutcome struct {
+	Messages []awsTypes.Message
+	Duration time.Duration
+	Err      error
+}
+
+// DeleteResult describes the result of a single delete request, a batch may be deleted in several requests
+type DeleteResult struct {
+	Deleted []string
+	Failed  []awsTypes.BatchResultErrorEntry
+}
+
+// NopObserver implements Observer with empty callbacks, embed it to only implement the callbacks needed
+type NopObserver struct{}
+
+func (NopObserver) OnPollStart(context.Context)                    {}
+func (NopObserver) OnReceived(context.Context, []awsTypes.Message) {}
+func (NopObserver) OnBatchHandled(context.Context, BatchOutcome)   {}
+func (NopObserver) OnDeleted(context.Context, DeleteResult)        {}
+func (NopObserver) OnError(context.Context, error)                 {}
+func (NopObserver) OnShutdown(context.Context)                     {}
+
+// observers fans out every callback to all registered observers in registration order
+type observers []Observer
+
+func (o observers) OnPollStart(ctx context.Context) {
+	for _, obs := range o {
+		obs.OnPollStart(ctx)
+	}
+}
+
+func (o observers) OnReceived(ctx context.Context, messages []awsTypes.Message) {
+	for _, obs := range o {
+		obs.OnReceived(ctx, messages)
+	}
+}
+
+func (o observers) OnBatchHandled(ctx context.Context, outcome BatchOutcome) {
+	for _, obs := range o {
+		obs.OnBatchHandled(ctx, outcome)
+	}
+}
+
+func (o observers) OnDeleted(ctx context.Context, result DeleteResult) {
+	for _, obs := range o {
+		obs.OnDeleted(ctx, result)
+	}
+}
+
+func (o observers) OnError(ctx context.Context, err error) {
+	for _, obs := range o {
+		obs.OnError(ctx, err)
+	}
+}
+
+func (o observers) OnShutdown(ctx context.Context) {
+	for _, obs := range o {
+		obs.OnShutdown(ctx)
+	}
+}
@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumer_ObserverBatchCycle(t *testing.T) {
+	messages := []types.Message{
+		{MessageId: aws.String("foo"), ReceiptHandle: aws.String("bar")},
+		{MessageId: aws.String("baz"), ReceiptHandle: aws.String("bar")},
+	}
+	expectedHandleErr := errors.New("foo bar baz")
+
+	_, cancel := context.WithCancel(context.Background())
+	client := &MockClient{cancel: cancel, messages: [][]types.Message{messages}}
+	handler := &MockBatchHandler{handleErr: expectedHandleErr}
+	first := &MockObserver{}
+	second := &MockObserver{}
+
+	consumer := Consumer{client: client, maxNumberOfMessages: 10, handler: handler}
+	consumer.AddObserver(first, second)
+	consumer.runBatch(context.Background())
+
+	for _, o := range []*MockObserver{first, second} {
+		assert.Equal(t, []string{"poll", "received", "handled", "deleted"}, o.events)
+		require.Len(t, o.outcomes, 1)
+		assert.Equal(t, messages, o.outcomes[0].Messages)
+		assert.Equal(t, expectedHandleErr, o.outcomes[0].Err)
+		require.Len(t, o.deleted, 1)
+		assert.Equal(t, []string{"foo", "baz"}, o.deleted[0].Deleted)
+	}
+}
+
+func TestConsumer_ObserverErrors(t *testing.T) {
+	expectedErr := errors.New("foo bar baz")
+	client := &MockClient{receiveErr: expectedErr}
+	observer := &MockObserver{}
+
+	consumer := Consumer{client: client, maxNumberOfMessages: 10, handler: &MockBatchHandler{}}
+	consumer.AddObserver(observer)
+	consumer.runBatch(context.Background())
+
+	assert.Equal(t, []string{"poll", "error"}, observer.events)
+	assert.Equal(t, []error{expectedErr}, observer.errors)
+}
+
+func TestConsumer_ObserverShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	observer := &MockObserver{}
+
+	consumer := Consumer{client: &MockClient{}, maxNumberOfMessages: 10}
+	consumer.AddObserver(observer)
+	consumer.Start(ctx)
+
+	assert.Equal(t, []string{"shutdown"}, observer.events)
+}
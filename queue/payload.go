@@ -0,0 +1,58 @@
+package queue
+
+import (
+	"context"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/blob"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// PayloadFetcher replaces the body of messages offloaded by the publisher with the payload from the blob store
+type PayloadFetcher struct {
+	store blob.Store
+	// DeleteBlobs removes the payload from the store once the message got deleted from the queue,
+	// do not enable it if the same payload is published to several queues
+	DeleteBlobs bool
+}
+
+func NewPayloadFetcher(store blob.Store, deleteBlobs bool) *PayloadFetcher {
+	return &PayloadFetcher{
+		store:       store,
+		DeleteBlobs: deleteBlobs,
+	}
+}
+
+func (f *PayloadFetcher) Decode(ctx context.Context, msg *awsTypes.Message) error {
+	key, ok := payloadPointer(*msg)
+	if !ok {
+		return nil
+	}
+
+	payload, err := f.store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	msg.Body = aws.String(string(payload))
+
+	return nil
+}
+
+func (f *PayloadFetcher) Release(ctx context.Context, msg awsTypes.Message) error {
+	key, ok := payloadPointer(msg)
+	if !f.DeleteBlobs || !ok {
+		return nil
+	}
+
+	return f.store.Delete(ctx, key)
+}
+
+func payloadPointer(msg awsTypes.Message) (string, bool) {
+	attr, ok := msg.MessageAttributes[blob.PointerAttribute]
+	if !ok || attr.StringValue == nil {
+		return "", false
+	}
+
+	return *attr.StringValue, true
+}
@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/blob"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pointerMessage(id string, key string) types.Message {
+	return types.Message{
+		MessageId:     aws.String(id),
+		ReceiptHandle: aws.String("bar"),
+		Body:          aws.String(key),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			blob.PointerAttribute: {DataType: aws.String("String"), StringValue: aws.String(key)},
+		},
+	}
+}
+
+func TestConsumer_PayloadFetcher(t *testing.T) {
+	ctx := context.Background()
+	store, err := blob.NewFileStore(t.TempDir())
+	require.Nil(t, err)
+	require.Nil(t, store.Put(ctx, "foo-key", []byte("foo payload")))
+
+	messages := []types.Message{
+		pointerMessage("foo", "foo-key"),
+		{MessageId: aws.String("bar"), ReceiptHandle: aws.String("bar"), Body: aws.String("bar payload")},
+	}
+
+	_, cancel := context.WithCancel(ctx)
+	client := &MockClient{cancel: cancel, messages: [][]types.Message{messages}}
+	handler := &MockBatchHandler{}
+
+	consumer := Consumer{client: client, maxNumberOfMessages: 10, handler: handler}
+	consumer.AddDecoder(NewPayloadFetcher(store, true))
+	consumer.runBatch(ctx)
+
+	require.Len(t, handler.received, 2)
+	assert.Equal(t, "foo payload", aws.ToString(handler.received[0].Body))
+	assert.Equal(t, "bar payload", aws.ToString(handler.received[1].Body))
+	assert.Len(t, client.deletedMessages, 2)
+
+	_, err = store.Get(ctx, "foo-key")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+}
+
+func TestConsumer_PayloadFetcherMissingBlob(t *testing.T) {
+	ctx := context.Background()
+	store, err := blob.NewFileStore(t.TempDir())
+	require.Nil(t, err)
+
+	_, cancel := context.WithCancel(ctx)
+	client := &MockClient{cancel: cancel, messages: [][]types.Message{{pointerMessage("foo", "foo-key")}}}
+	handler := &MockBatchHandler{}
+	observer := &MockObserver{}
+
+	consumer := Consumer{client: client, maxNumberOfMessages: 10, handler: handler}
+	consumer.AddDecoder(NewPayloadFetcher(store, true))
+	consumer.AddObserver(observer)
+	consumer.runBatch(ctx)
+
+	assert.Empty(t, handler.received)
+	assert.Empty(t, client.deletedMessages)
+	require.Len(t, observer.errors, 1)
+	assert.ErrorIs(t, observer.errors[0], blob.ErrNotFound)
+}
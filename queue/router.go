@@ -0,0 +1,40 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Router is a SingleHandler passing messages to the handler registered for their Message-Type attribute
+type Router struct {
+	handlers map[string]SingleHandler
+	// Fallback handles messages of types without handler, if not set these fail with an error
+	Fallback SingleHandler
+}
+
+func NewRouter() *Router {
+	return &Router{
+		handlers: map[string]SingleHandler{},
+	}
+}
+
+func (r *Router) Route(messageType string, handler SingleHandler) *Router {
+	r.handlers[messageType] = handler
+
+	return r
+}
+
+func (r *Router) Handle(ctx context.Context, msg awsTypes.Message) error {
+	handler, ok := r.handlers[MessageType(msg)]
+	if !ok {
+		handler = r.Fallback
+	}
+
+	if handler == nil {
+		return fmt.Errorf("consumer: no handler for message type '%s'", MessageType(msg))
+	}
+
+	return handler.Handle(ctx, msg)
+}
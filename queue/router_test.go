@@ -0,0 +1,54 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/codec"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type priceChanged struct {
+	SKU string `json:"sku"`
+}
+
+func messageOfType(messageType string, body string) types.Message {
+	return types.Message{
+		Body: aws.String(body),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			utils.MessageTypeAttribute: {DataType: aws.String("String"), StringValue: aws.String(messageType)},
+		},
+	}
+}
+
+func TestRouter_Handle(t *testing.T) {
+	price := &MockSingleHandler{mx: sync.RWMutex{}}
+	fallback := &MockSingleHandler{mx: sync.RWMutex{}}
+	router := NewRouter().Route("price", price)
+
+	require.Nil(t, router.Handle(context.Background(), messageOfType("price", "foo")))
+	assert.Error(t, router.Handle(context.Background(), messageOfType("stock", "bar")))
+
+	router.Fallback = fallback
+	require.Nil(t, router.Handle(context.Background(), messageOfType("stock", "bar")))
+
+	assert.Len(t, price.received, 1)
+	assert.Len(t, fallback.received, 1)
+}
+
+func TestUnmarshalTyped(t *testing.T) {
+	typeRegistry := codec.NewTypeRegistry()
+	typeRegistry.Register("price", priceChanged{})
+
+	v, err := UnmarshalTyped(codec.DefaultRegistry(), typeRegistry, messageOfType("price", `{"sku":"foo"}`))
+	require.Nil(t, err)
+	assert.Equal(t, &priceChanged{SKU: "foo"}, v)
+
+	_, err = UnmarshalTyped(codec.DefaultRegistry(), typeRegistry, messageOfType("stock", `{}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// ErrNotDue is wrapped by decoder errors of messages deferred till later, the consumer does not report them as failures
+var ErrNotDue = errors.New("message not due yet")
+
+// maximum timeouts supported by SQS
+const (
+	maxVisibilityTimeout = 12 * 60 * 60
+	maxDelaySeconds      = 15 * 60
+	maxWaitTimeSeconds   = 20
+)
+
+// visibilityMargin is kept off the visibility limit of a receive for clock skew between SQS and the consumer
+const visibilityMargin = time.Minute
+
+type receiveTimeKey struct{}
+
+// withReceiveTime returns a context carrying the time the messages decoded with it were received
+func withReceiveTime(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, receiveTimeKey{}, t)
+}
+
+// receiveTime returns the time the messages were received, now if the context does not tell
+func receiveTime(ctx context.Context) time.Time {
+	if t, ok := ctx.Value(receiveTimeKey{}).(time.Time); ok {
+		return t
+	}
+
+	return time.Now()
+}
+
+// SQSScheduleClient is the part of the SQS API needed to defer messages
+type SQSScheduleClient interface {
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+}
+
+// Scheduler is a MessageDecoder deferring messages with a Deliver-At attribute in the future, the handler only
+// gets them once due. It has to be the first decoder as deferred messages are sent on as received.
+type Scheduler struct {
+	client SQSScheduleClient
+	// queueURL resolves the URL of the queue on use, it may not be resolved yet when the scheduler is created
+	queueURL func(ctx context.Context) (string, error)
+	// Requeue sends a delayed copy of messages not due and deletes them instead of extending their visibility.
+	// It keeps the receive count low for long schedules but only works for standard queues.
+	Requeue bool
+}
+
+// NewScheduler defers messages of the queue by extending their visibility as far as SQS allows, 12 hours from their
+// receive. Pass Consumer.ResolveQueueURL as queueURL, or the Get method of a utils.QueueURL.
+// Every receive after an extension counts towards the maxReceiveCount of a redrive policy, so messages scheduled
+// further ahead than about maxReceiveCount times 12 hours are moved to the dead-letter queue before they are due.
+// Set Requeue for such queues.
+func NewScheduler(client SQSScheduleClient, queueURL func(ctx context.Context) (string, error)) *Scheduler {
+	return &Scheduler{
+		client:   client,
+		queueURL: queueURL,
+	}
+}
+
+func (s *Scheduler) Decode(ctx context.Context, msg *awsTypes.Message) error {
+	value, ok := msg.MessageAttributes[utils.DeliverAtAttribute]
+	if !ok {
+		return nil
+	}
+
+	deliverAt, err := time.Parse(time.RFC3339Nano, aws.ToString(value.StringValue))
+	if err != nil {
+		return fmt.Errorf("invalid %s attribute: %w", utils.DeliverAtAttribute, err)
+	}
+
+	remaining := math.Ceil(time.Until(deliverAt).Seconds())
+	if remaining <= 0 {
+		return nil
+	}
+
+	if s.Requeue {
+		return s.requeue(ctx, msg, int32(math.Min(remaining, maxDelaySeconds)))
+	}
+
+	// SQS limits the visibility to 12 hours from the receive, not from the change
+	limit := math.Floor((maxVisibilityTimeout*time.Second - time.Since(receiveTime(ctx)) - visibilityMargin).Seconds())
+	if limit <= 0 {
+		// the message gets visible again once its current timeout expires
+		return fmt.Errorf("%w: due at %s", ErrNotDue, deliverAt)
+	}
+
+	queueURL, err := s.queueURL(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(queueURL),
+		ReceiptHandle:     msg.ReceiptHandle,
+		VisibilityTimeout: int32(math.Min(remaining, limit)),
+	})
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: due at %s", ErrNotDue, deliverAt)
+}
+
+// requeue sends a copy delayed for the seconds and deletes the received message.
+// It is deleted here rather than by the consumer, which would release it, e.g. delete the offloaded payload of the copy.
+func (s *Scheduler) requeue(ctx context.Context, msg *awsTypes.Message, delay int32) error {
+	queueURL, err := s.queueURL(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:          aws.String(queueURL),
+		MessageBody:       msg.Body,
+		MessageAttributes: msg.MessageAttributes,
+		DelaySeconds:      delay,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(queueURL),
+		ReceiptHandle: msg.ReceiptHandle,
+	})
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: requeued for %d seconds", ErrNotDue, delay)
+}
@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scheduledMessage(id string, deliverAt time.Time) types.Message {
+	return types.Message{
+		MessageId:     aws.String(id),
+		ReceiptHandle: aws.String("receipt-" + id),
+		Body:          aws.String("foo"),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			utils.DeliverAtAttribute: {DataType: aws.String("String"), StringValue: aws.String(deliverAt.Format(time.RFC3339Nano))},
+		},
+	}
+}
+
+func TestScheduler_ExtendsVisibilityOfMessagesNotDue(t *testing.T) {
+	messages := []types.Message{
+		scheduledMessage("due", time.Now().Add(-time.Minute)),
+		scheduledMessage("later", time.Now().Add(time.Hour)),
+		scheduledMessage("days", time.Now().Add(48*time.Hour)),
+		{MessageId: aws.String("plain"), ReceiptHandle: aws.String("bar"), Body: aws.String("foo")},
+	}
+	_, cancel := context.WithCancel(context.Background())
+	client := &MockClient{cancel: cancel, messages: [][]types.Message{messages}, queueUrl: "https://foo.bar/baz"}
+	scheduleClient := &MockScheduleClient{}
+	handler := &MockBatchHandler{}
+	observer := &MockObserver{}
+
+	// the queue URL is resolved lazily after the scheduler is created
+	consumer := Consumer{client: client, queueURL: utils.NewQueueURL(client, "baz", ""), maxNumberOfMessages: 10, handler: handler}
+	consumer.AddObserver(observer)
+	consumer.AddDecoder(NewScheduler(scheduleClient, consumer.ResolveQueueURL))
+	require.Empty(t, consumer.QueueURL())
+	consumer.runBatch(context.Background())
+
+	require.Len(t, handler.received, 2)
+	assert.Equal(t, "due", aws.ToString(handler.received[0].MessageId))
+	assert.Equal(t, "plain", aws.ToString(handler.received[1].MessageId))
+	assert.ElementsMatch(t, []*string{aws.String("due"), aws.String("plain")}, client.deletedMessages)
+	assert.Empty(t, observer.errors)
+
+	require.Len(t, scheduleClient.visibilities, 2)
+	assert.Equal(t, "receipt-later", aws.ToString(scheduleClient.visibilities[0].ReceiptHandle))
+	assert.Equal(t, "https://foo.bar/baz", aws.ToString(scheduleClient.visibilities[0].QueueUrl))
+	assert.InDelta(t, 3600, scheduleClient.visibilities[0].VisibilityTimeout, 1)
+	assert.InDelta(t, maxVisibilityTimeout-visibilityMargin.Seconds(), scheduleClient.visibilities[1].VisibilityTimeout, 1)
+}
+
+func TestScheduler_CapsVisibilityFromReceive(t *testing.T) {
+	scheduleClient := &MockScheduleClient{}
+	scheduler := NewScheduler(scheduleClient, utils.NewQueueURL(nil, "https://foo.bar/baz", "").Get)
+
+	msg := scheduledMessage("days", time.Now().Add(36*time.Hour))
+	ctx := withReceiveTime(context.Background(), time.Now().Add(-time.Hour))
+	assert.ErrorIs(t, scheduler.Decode(ctx, &msg), ErrNotDue)
+
+	require.Len(t, scheduleClient.visibilities, 1)
+	assert.InDelta(t, maxVisibilityTimeout-3600-visibilityMargin.Seconds(), scheduleClient.visibilities[0].VisibilityTimeout, 1)
+
+	// no extension is left once the receive is 12 hours ago
+	ctx = withReceiveTime(context.Background(), time.Now().Add(-12*time.Hour))
+	assert.ErrorIs(t, scheduler.Decode(ctx, &msg), ErrNotDue)
+	assert.Len(t, scheduleClient.visibilities, 1)
+}
+
+func TestScheduler_RequeuesMessagesNotDue(t *testing.T) {
+	scheduleClient := &MockScheduleClient{}
+	scheduler := NewScheduler(scheduleClient, utils.NewQueueURL(nil, "https://foo.bar/baz", "").Get)
+	scheduler.Requeue = true
+
+	msg := scheduledMessage("later", time.Now().Add(time.Hour))
+	err := scheduler.Decode(context.Background(), &msg)
+
+	assert.ErrorIs(t, err, ErrNotDue)
+	assert.NotErrorIs(t, err, ErrDiscard)
+	require.Len(t, scheduleClient.sent, 1)
+	assert.Equal(t, int32(maxDelaySeconds), scheduleClient.sent[0].DelaySeconds)
+	assert.Equal(t, msg.MessageAttributes, scheduleClient.sent[0].MessageAttributes)
+	require.Len(t, scheduleClient.deleted, 1)
+	assert.Equal(t, "receipt-later", aws.ToString(scheduleClient.deleted[0].ReceiptHandle))
+}
+
+func TestScheduler_InvalidDeliverAt(t *testing.T) {
+	msg := types.Message{MessageAttributes: map[string]types.MessageAttributeValue{
+		utils.DeliverAtAttribute: {DataType: aws.String("String"), StringValue: aws.String("tomorrow")},
+	}}
+
+	assert.Error(t, NewScheduler(&MockScheduleClient{}, utils.NewQueueURL(nil, "https://foo.bar/baz", "").Get).Decode(context.Background(), &msg))
+}
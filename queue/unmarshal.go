@@ -0,0 +1,39 @@
+package queue
+
+import (
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/codec"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// Unmarshal decodes the message body into v with the codec named by its Content-Type attribute.
+// Messages without the attribute are decoded as JSON.
+func Unmarshal(registry *codec.Registry, msg awsTypes.Message, v interface{}) error {
+	contentType := codec.JSON.ContentType()
+	if attr, ok := msg.MessageAttributes[utils.ContentTypeAttribute]; ok && attr.StringValue != nil {
+		contentType = *attr.StringValue
+	}
+
+	return registry.Unmarshal(contentType, aws.ToString(msg.Body), v)
+}
+
+// UnmarshalTyped decodes the message body into a new value of the Go type registered for its Message-Type attribute
+// and returns a pointer to it
+func UnmarshalTyped(registry *codec.Registry, types *codec.TypeRegistry, msg awsTypes.Message) (interface{}, error) {
+	v, err := types.New(MessageType(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = Unmarshal(registry, msg, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// MessageType returns the Message-Type attribute of the message
+func MessageType(msg awsTypes.Message) string {
+	return aws.ToString(msg.MessageAttributes[utils.MessageTypeAttribute].StringValue)
+}
@@ -0,0 +1,33 @@
+package queue
+
+import (
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/codec"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshal(t *testing.T) {
+	registry := codec.DefaultRegistry()
+	body, err := codec.MessagePack.Marshal(map[string]string{"foo": "bar"})
+	require.Nil(t, err)
+
+	msg := types.Message{
+		Body: aws.String(body),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			utils.ContentTypeAttribute: {DataType: aws.String("String"), StringValue: aws.String("application/msgpack")},
+		},
+	}
+
+	actual := map[string]string{}
+	require.Nil(t, Unmarshal(registry, msg, &actual))
+	assert.Equal(t, map[string]string{"foo": "bar"}, actual)
+
+	actual = map[string]string{}
+	require.Nil(t, Unmarshal(registry, types.Message{Body: aws.String(`{"foo":"baz"}`)}, &actual))
+	assert.Equal(t, map[string]string{"foo": "baz"}, actual)
+}
@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/schema"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// FailurePolicy decides what happens to a message that failed validation
+type FailurePolicy interface {
+	// Reject returns true if the message is to be deleted from the queue, false to retain it for redelivery or redrive
+	Reject(ctx context.Context, msg awsTypes.Message, err error) bool
+}
+
+// RetainPolicy leaves invalid messages in the queue, a redrive policy eventually moves them to the dead letter queue
+type RetainPolicy struct{}
+
+func (RetainPolicy) Reject(context.Context, awsTypes.Message, error) bool {
+	return false
+}
+
+// DropPolicy deletes invalid messages from the queue
+type DropPolicy struct{}
+
+func (DropPolicy) Reject(context.Context, awsTypes.Message, error) bool {
+	return true
+}
+
+// HandlerPolicy passes invalid messages to a separate handler, e.g. publishing them to an error queue.
+// The message is deleted if the handler succeeds and retained otherwise.
+type HandlerPolicy struct {
+	Handler SingleHandler
+}
+
+func (p HandlerPolicy) Reject(ctx context.Context, msg awsTypes.Message, _ error) bool {
+	return p.Handler.Handle(ctx, msg) == nil
+}
+
+// Validator checks message bodies against the schema registered for their Message-Type attribute, see
+// schema.Registry.ValidateContent for bodies which are not JSON
+type Validator struct {
+	registry *schema.Registry
+	policy   FailurePolicy
+}
+
+func NewValidator(registry *schema.Registry, policy FailurePolicy) *Validator {
+	return &Validator{
+		registry: registry,
+		policy:   policy,
+	}
+}
+
+func (v *Validator) Decode(ctx context.Context, msg *awsTypes.Message) error {
+	contentType := aws.ToString(msg.MessageAttributes[utils.ContentTypeAttribute].StringValue)
+	err := v.registry.ValidateContent(MessageType(*msg), contentType, aws.ToString(msg.Body))
+	if err == nil {
+		return nil
+	}
+
+	if v.policy.Reject(ctx, *msg, err) {
+		return fmt.Errorf("%w: %w", ErrDiscard, err)
+	}
+
+	return err
+}
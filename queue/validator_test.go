@@ -0,0 +1,71 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/schema"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func typedMessage(id string, body string) types.Message {
+	return types.Message{
+		MessageId:     aws.String(id),
+		ReceiptHandle: aws.String("bar"),
+		Body:          aws.String(body),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			utils.MessageTypeAttribute: {DataType: aws.String("String"), StringValue: aws.String("foo")},
+		},
+	}
+}
+
+func runValidated(t *testing.T, policy FailurePolicy) (*MockClient, *MockBatchHandler) {
+	registry := schema.NewRegistry()
+	require.Nil(t, registry.Register("foo", `{"type":"object","required":["foo"]}`))
+
+	messages := []types.Message{typedMessage("valid", `{"foo":1}`), typedMessage("invalid", `{"bar":1}`)}
+	_, cancel := context.WithCancel(context.Background())
+	client := &MockClient{cancel: cancel, messages: [][]types.Message{messages}}
+	handler := &MockBatchHandler{}
+
+	consumer := Consumer{client: client, maxNumberOfMessages: 10, handler: handler}
+	consumer.AddDecoder(NewValidator(registry, policy))
+	consumer.runBatch(context.Background())
+
+	require.Len(t, handler.received, 1)
+	assert.Equal(t, "valid", aws.ToString(handler.received[0].MessageId))
+
+	return client, handler
+}
+
+func TestValidator_RetainPolicy(t *testing.T) {
+	client, _ := runValidated(t, RetainPolicy{})
+
+	assert.Equal(t, []*string{aws.String("valid")}, client.deletedMessages)
+}
+
+func TestValidator_DropPolicy(t *testing.T) {
+	client, _ := runValidated(t, DropPolicy{})
+
+	assert.ElementsMatch(t, []*string{aws.String("valid"), aws.String("invalid")}, client.deletedMessages)
+}
+
+func TestValidator_HandlerPolicy(t *testing.T) {
+	invalid := &MockSingleHandler{mx: sync.RWMutex{}}
+	client, _ := runValidated(t, HandlerPolicy{Handler: invalid})
+
+	require.Len(t, invalid.received, 1)
+	assert.Equal(t, "invalid", aws.ToString(invalid.received[0].MessageId))
+	assert.Len(t, client.deletedMessages, 2)
+
+	failing := &MockSingleHandler{mx: sync.RWMutex{}, handleErr: errors.New("foo")}
+	client, _ = runValidated(t, HandlerPolicy{Handler: failing})
+
+	assert.Len(t, client.deletedMessages, 1)
+}
@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+type MockClient struct {
+	sent    []*sqs.SendMessageInput
+	sendErr error
+	mx      sync.Mutex
+}
+
+func (m *MockClient) SendMessage(_ context.Context, input *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.sent = append(m.sent, input)
+
+	return &sqs.SendMessageOutput{MessageId: aws.String("foo")}, m.sendErr
+}
+
+func (m *MockClient) SendMessageBatch(context.Context, *sqs.SendMessageBatchInput, ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	return &sqs.SendMessageBatchOutput{}, nil
+}
+
+func (m *MockClient) GetQueueUrl(context.Context, *sqs.GetQueueUrlInput, ...func(o *sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+	return &sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://foo.bar/requests")}, nil
+}
+
+// received returns the n-th sent message as it would be received
+func (m *MockClient) received(n int) awsTypes.Message {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	return awsTypes.Message{
+		Body:              m.sent[n].MessageBody,
+		MessageAttributes: m.sent[n].MessageAttributes,
+	}
+}
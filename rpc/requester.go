@@ -0,0 +1,177 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/publish"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrTimeout = errors.New("rpc: no reply within timeout")
+
+// ErrUnknownReply is returned by Requester.Decode for replies to requests of other requesters, so the consumer
+// retains them for the requester waiting for them
+var ErrUnknownReply = errors.New("rpc: reply to a request of another requester")
+
+// Future is completed with the reply to a request or ErrTimeout
+type Future struct {
+	CorrelationID string
+	done          chan struct{}
+	reply         awsTypes.Message
+	err           error
+	timer         *time.Timer
+	once          sync.Once
+}
+
+func newFuture(correlationID string) *Future {
+	return &Future{CorrelationID: correlationID, done: make(chan struct{})}
+}
+
+func (f *Future) complete(reply awsTypes.Message, err error) {
+	f.once.Do(func() {
+		f.reply = reply
+		f.err = err
+		close(f.done)
+	})
+}
+
+// Done is closed when the reply arrived or the request timed out
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks till the reply arrived, the request timed out or the context is done
+func (f *Future) Wait(ctx context.Context) (awsTypes.Message, error) {
+	select {
+	case <-f.done:
+		return f.reply, f.err
+	case <-ctx.Done():
+		return awsTypes.Message{}, ctx.Err()
+	}
+}
+
+// Requester sends requests carrying the reply queue and a correlation id and completes their futures
+// with the replies. It is the handler of the consumer of the reply queue, shared by all requests, and has to be
+// added as decoder of that consumer to retain replies to other requesters, as handled messages are deleted.
+// Each instance should have a reply queue of its own: replies to other requesters are retained till their visibility
+// timeout expires, a reply queue shared by instances delays their replies by up to the visibility timeout each.
+type Requester struct {
+	publisher     *publish.Publisher
+	replyQueueURL string
+	// instanceID prefixes the correlation ids to tell late replies from replies to other requesters
+	instanceID string
+	// Timeout after which a request without reply fails with ErrTimeout
+	Timeout time.Duration
+	pending map[string]*Future
+	mx      sync.Mutex
+}
+
+func NewRequester(publisher *publish.Publisher, replyQueueURL string) *Requester {
+	return &Requester{
+		publisher:     publisher,
+		replyQueueURL: replyQueueURL,
+		instanceID:    uuid.Must(uuid.NewV4()).String(),
+		Timeout:       30 * time.Second,
+		pending:       map[string]*Future{},
+	}
+}
+
+// Request publishes the message and returns the future of its reply
+func (r *Requester) Request(ctx context.Context, message interface{}, opts ...publish.PublishOption) (*Future, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	future := newFuture(r.instanceID + "/" + id.String())
+	r.mx.Lock()
+	r.pending[future.CorrelationID] = future
+	// the timeout includes publishing, a reply may arrive before Publish returns
+	future.timer = time.AfterFunc(r.Timeout, func() {
+		r.remove(future.CorrelationID)
+		future.complete(awsTypes.Message{}, ErrTimeout)
+	})
+	r.mx.Unlock()
+
+	opts = append(opts,
+		publish.WithStringAttribute(utils.ReplyToAttribute, r.replyQueueURL),
+		publish.WithStringAttribute(utils.CorrelationIDAttribute, future.CorrelationID),
+	)
+	if _, err = r.publisher.Publish(ctx, message, opts...); err != nil {
+		future.timer.Stop()
+		r.remove(future.CorrelationID)
+		return nil, err
+	}
+
+	return future, nil
+}
+
+// Call publishes the message and waits for its reply
+func (r *Requester) Call(ctx context.Context, message interface{}, opts ...publish.PublishOption) (awsTypes.Message, error) {
+	future, err := r.Request(ctx, message, opts...)
+	if err != nil {
+		return awsTypes.Message{}, err
+	}
+
+	return future.Wait(ctx)
+}
+
+// Decode fails replies to requests of other requesters with ErrUnknownReply, the consumer retains them in the queue
+func (r *Requester) Decode(_ context.Context, msg *awsTypes.Message) error {
+	if correlationID := attribute(*msg, utils.CorrelationIDAttribute); !r.owns(correlationID) {
+		return fmt.Errorf("%w: '%s'", ErrUnknownReply, correlationID)
+	}
+
+	return nil
+}
+
+// Handle completes the future waiting for the reply. Replies to timed out requests are dropped, replies to requests
+// of other requesters fail with ErrUnknownReply but are deleted nevertheless unless Decode rejected them.
+func (r *Requester) Handle(_ context.Context, msg awsTypes.Message) error {
+	correlationID := attribute(msg, utils.CorrelationIDAttribute)
+	if !r.owns(correlationID) {
+		return fmt.Errorf("%w: '%s'", ErrUnknownReply, correlationID)
+	}
+
+	future := r.remove(correlationID)
+	if future == nil {
+		logrus.Debugf("rpc: dropping reply to timed out request '%s'", correlationID)
+		return nil
+	}
+
+	future.timer.Stop()
+	future.complete(msg, nil)
+
+	return nil
+}
+
+// owns tells whether the correlation id belongs to a request of this requester
+func (r *Requester) owns(correlationID string) bool {
+	return strings.HasPrefix(correlationID, r.instanceID+"/")
+}
+
+func (r *Requester) remove(correlationID string) *Future {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	future := r.pending[correlationID]
+	delete(r.pending, correlationID)
+
+	return future
+}
+
+func attribute(msg awsTypes.Message, name string) string {
+	if value, ok := msg.MessageAttributes[name]; ok && value.StringValue != nil {
+		return *value.StringValue
+	}
+
+	return ""
+}
@@ -0,0 +1,95 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/publish"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// ErrReplyToNotAllowed is returned for requests whose Reply-To queue is rejected by the responder's ReplyTo validator
+var ErrReplyToNotAllowed = errors.New("rpc: reply queue not allowed")
+
+// ReplyToValidator decides whether replies may be published to the queue URL of a Reply-To attribute
+type ReplyToValidator func(queueURL string) bool
+
+// AllowReplyQueues only allows replies to the given queue URLs
+func AllowReplyQueues(queueURLs ...string) ReplyToValidator {
+	allowed := map[string]bool{}
+	for _, queueURL := range queueURLs {
+		allowed[queueURL] = true
+	}
+
+	return func(queueURL string) bool {
+		return allowed[queueURL]
+	}
+}
+
+// ReplyHandler handles a request and returns the reply, nil to not reply
+type ReplyHandler interface {
+	Handle(ctx context.Context, msg awsTypes.Message) (interface{}, error)
+}
+
+// ReplyHandlerFunc adapts a function to ReplyHandler
+type ReplyHandlerFunc func(ctx context.Context, msg awsTypes.Message) (interface{}, error)
+
+func (f ReplyHandlerFunc) Handle(ctx context.Context, msg awsTypes.Message) (interface{}, error) {
+	return f(ctx, msg)
+}
+
+// Responder is a queue.SingleHandler publishing the replies of its handler to the Reply-To queue of the requests.
+// Requests without Reply-To are handled without reply, requests failed by the handler are not replied to.
+// Added as decoder of the consumer too it retains requests with rejected Reply-To queues instead of having them deleted.
+type Responder struct {
+	// ReplyTo validates the Reply-To queues before the request is handled, requests to other queues fail with
+	// ErrReplyToNotAllowed. If not set replies are published to any queue the publisher's credentials allow.
+	ReplyTo   ReplyToValidator
+	handler   ReplyHandler
+	publisher *publish.Publisher
+}
+
+// NewResponder publishes replies with a copy of the publisher sending to the reply queue
+func NewResponder(handler ReplyHandler, publisher *publish.Publisher) *Responder {
+	return &Responder{
+		handler:   handler,
+		publisher: publisher,
+	}
+}
+
+// Decode fails requests whose Reply-To queue is not allowed with ErrReplyToNotAllowed, the consumer retains them
+func (r *Responder) Decode(_ context.Context, msg *awsTypes.Message) error {
+	return r.checkReplyTo(attribute(*msg, utils.ReplyToAttribute))
+}
+
+func (r *Responder) Handle(ctx context.Context, msg awsTypes.Message) error {
+	replyTo := attribute(msg, utils.ReplyToAttribute)
+	if err := r.checkReplyTo(replyTo); err != nil {
+		return err
+	}
+
+	reply, err := r.handler.Handle(ctx, msg)
+	if err != nil {
+		return err
+	}
+
+	if reply == nil || replyTo == "" {
+		return nil
+	}
+
+	_, err = r.publisher.WithQueueURL(replyTo).Publish(ctx, reply,
+		publish.WithStringAttribute(utils.CorrelationIDAttribute, attribute(msg, utils.CorrelationIDAttribute)),
+	)
+
+	return err
+}
+
+func (r *Responder) checkReplyTo(replyTo string) error {
+	if replyTo != "" && r.ReplyTo != nil && !r.ReplyTo(replyTo) {
+		return fmt.Errorf("%w: %s", ErrReplyToNotAllowed, replyTo)
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/publish"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/queue"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/sqstest"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	awsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPublisher(t *testing.T, client *MockClient) *publish.Publisher {
+	publisher, err := publish.NewPublisher(publish.PublisherConfig{QueueName: "requests"}, client)
+	require.Nil(t, err)
+
+	return publisher
+}
+
+func TestRequester_RequestCompletedByReply(t *testing.T) {
+	client := &MockClient{}
+	requester := NewRequester(newPublisher(t, client), "https://foo.bar/replies")
+	responder := NewResponder(ReplyHandlerFunc(func(_ context.Context, msg awsTypes.Message) (interface{}, error) {
+		return "re: " + aws.ToString(msg.Body), nil
+	}), newPublisher(t, client))
+
+	future, err := requester.Request(context.Background(), "foo")
+	require.Nil(t, err)
+
+	request := client.received(0)
+	assert.Equal(t, "https://foo.bar/requests", aws.ToString(client.sent[0].QueueUrl))
+	assert.Equal(t, "https://foo.bar/replies", aws.ToString(request.MessageAttributes[utils.ReplyToAttribute].StringValue))
+	assert.Equal(t, future.CorrelationID, aws.ToString(request.MessageAttributes[utils.CorrelationIDAttribute].StringValue))
+
+	require.Nil(t, responder.Handle(context.Background(), request))
+	require.Len(t, client.sent, 2)
+	assert.Equal(t, "https://foo.bar/replies", aws.ToString(client.sent[1].QueueUrl))
+
+	require.Nil(t, requester.Handle(context.Background(), client.received(1)))
+
+	reply, err := future.Wait(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, "re: foo", aws.ToString(reply.Body))
+}
+
+func TestRequester_RequestTimesOut(t *testing.T) {
+	client := &MockClient{}
+	requester := NewRequester(newPublisher(t, client), "https://foo.bar/replies")
+	requester.Timeout = 10 * time.Millisecond
+
+	_, err := requester.Call(context.Background(), "foo")
+	assert.ErrorIs(t, err, ErrTimeout)
+
+	// late replies are dropped
+	reply := client.received(0)
+	assert.Nil(t, requester.Handle(context.Background(), reply))
+	assert.Empty(t, requester.pending)
+}
+
+func TestRequester_HandleRetainsRepliesOfOtherRequesters(t *testing.T) {
+	client := &MockClient{}
+	requester := NewRequester(newPublisher(t, client), "https://foo.bar/replies")
+	other := NewRequester(newPublisher(t, client), "https://foo.bar/replies")
+
+	future, err := other.Request(context.Background(), "foo")
+	require.Nil(t, err)
+	reply := awsTypes.Message{MessageAttributes: map[string]awsTypes.MessageAttributeValue{
+		utils.CorrelationIDAttribute: publish.StringAttribute(future.CorrelationID),
+	}}
+
+	assert.ErrorIs(t, requester.Decode(context.Background(), &reply), ErrUnknownReply)
+	assert.ErrorIs(t, requester.Handle(context.Background(), reply), ErrUnknownReply)
+	require.Nil(t, other.Decode(context.Background(), &reply))
+	assert.Nil(t, other.Handle(context.Background(), reply))
+	_, err = future.Wait(context.Background())
+	assert.Nil(t, err)
+}
+
+// decodingHandler is a handler which also has to be added as decoder of its consumer
+type decodingHandler interface {
+	queue.SingleHandler
+	queue.MessageDecoder
+}
+
+// startConsumer consumes the queue with the handler till the returned stop function is called
+func startConsumer(t *testing.T, client *sqstest.Client, queueName string, handler decodingHandler) (stop func()) {
+	config := queue.ConsumerConfig{QueueName: queueName, MaxNumberOfMessages: 10, WaitTimeSeconds: 1, VisibilityTimeout: 30}
+	consumer, err := queue.NewConsumer(config, client, queue.Wrap(handler))
+	require.Nil(t, err)
+	consumer.AddDecoder(handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		consumer.Start(ctx)
+		close(done)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func messageCounts(t *testing.T, client *sqstest.Client, queueURL string) (visible string, inFlight string) {
+	output, err := client.GetQueueAttributes(context.Background(), &sqs.GetQueueAttributesInput{
+		QueueUrl: aws.String(queueURL), AttributeNames: []awsTypes.QueueAttributeName{awsTypes.QueueAttributeNameAll},
+	})
+	require.Nil(t, err)
+
+	return output.Attributes["ApproximateNumberOfMessages"], output.Attributes["ApproximateNumberOfMessagesNotVisible"]
+}
+
+func TestRequester_SharedReplyQueue(t *testing.T) {
+	client := sqstest.NewClient()
+	requestQueueURL := client.MustCreateQueue("requests", nil)
+	replyQueueURL := client.MustCreateQueue("replies", nil)
+	publisher, err := publish.NewPublisher(publish.PublisherConfig{QueueName: "requests"}, client)
+	require.Nil(t, err)
+
+	responder := NewResponder(ReplyHandlerFunc(func(_ context.Context, msg awsTypes.Message) (interface{}, error) {
+		return "re: " + aws.ToString(msg.Body), nil
+	}), publisher)
+	responder.ReplyTo = AllowReplyQueues(replyQueueURL)
+	defer startConsumer(t, client, "requests", responder)()
+
+	requester, other := NewRequester(publisher, replyQueueURL), NewRequester(publisher, replyQueueURL)
+	stop := startConsumer(t, client, "replies", requester)
+
+	otherFuture, err := other.Request(context.Background(), "bar")
+	require.Nil(t, err)
+	reply, err := requester.Call(context.Background(), "foo")
+	require.Nil(t, err)
+	assert.Equal(t, "re: foo", aws.ToString(reply.Body))
+
+	// the reply to the other requester is retained for it
+	require.Eventually(t, func() bool {
+		visible, inFlight := messageCounts(t, client, replyQueueURL)
+		return visible == "0" && inFlight == "1"
+	}, 5*time.Second, 10*time.Millisecond)
+	stop()
+
+	client.Advance(time.Minute)
+	defer startConsumer(t, client, "replies", other)()
+	reply, err = otherFuture.Wait(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, "re: bar", aws.ToString(reply.Body))
+
+	// requests to other reply queues are retained instead of being handled
+	_, err = publisher.Publish(context.Background(), "baz", publish.WithStringAttribute(utils.ReplyToAttribute, "https://evil.example/queue"))
+	require.Nil(t, err)
+	require.Eventually(t, func() bool {
+		visible, inFlight := messageCounts(t, client, requestQueueURL)
+		return visible == "0" && inFlight == "1"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRequester_RequestPublishError(t *testing.T) {
+	client := &MockClient{sendErr: errors.New("foo")}
+	requester := NewRequester(newPublisher(t, client), "https://foo.bar/replies")
+
+	_, err := requester.Request(context.Background(), "foo")
+
+	assert.EqualError(t, err, "foo")
+	assert.Empty(t, requester.pending)
+}
+
+func TestResponder_HandleWithoutReplyTo(t *testing.T) {
+	client := &MockClient{}
+	responder := NewResponder(ReplyHandlerFunc(func(context.Context, awsTypes.Message) (interface{}, error) {
+		return "bar", nil
+	}), newPublisher(t, client))
+
+	require.Nil(t, responder.Handle(context.Background(), awsTypes.Message{Body: aws.String("foo")}))
+	assert.Empty(t, client.sent)
+}
+
+func TestResponder_HandleRejectsReplyTo(t *testing.T) {
+	client := &MockClient{}
+	handled := 0
+	responder := NewResponder(ReplyHandlerFunc(func(context.Context, awsTypes.Message) (interface{}, error) {
+		handled++
+		return "bar", nil
+	}), newPublisher(t, client))
+	responder.ReplyTo = AllowReplyQueues("https://foo.bar/replies")
+
+	request := func(replyTo string) awsTypes.Message {
+		return awsTypes.Message{Body: aws.String("foo"), MessageAttributes: map[string]awsTypes.MessageAttributeValue{
+			utils.ReplyToAttribute: publish.StringAttribute(replyTo),
+		}}
+	}
+
+	assert.ErrorIs(t, responder.Handle(context.Background(), request("https://evil.example/queue")), ErrReplyToNotAllowed)
+	assert.Equal(t, 0, handled)
+	assert.Empty(t, client.sent)
+
+	require.Nil(t, responder.Handle(context.Background(), request("https://foo.bar/replies")))
+	assert.Equal(t, 1, handled)
+	require.Len(t, client.sent, 1)
+	assert.Equal(t, "https://foo.bar/replies", aws.ToString(client.sent[0].QueueUrl))
+}
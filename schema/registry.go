@@ -0,0 +1,134 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Registry holds JSON schemas keyed by the Message-Type attribute
+type Registry struct {
+	// Strict rejects messages of types without registered schema instead of accepting them
+	Strict bool
+
+	schemas map[string]*jsonschema.Schema
+	mx      sync.RWMutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		schemas: map[string]*jsonschema.Schema{},
+	}
+}
+
+// Register compiles the JSON schema document and uses it for all messages of messageType
+func (r *Registry) Register(messageType string, schema string) error {
+	compiled, err := jsonschema.CompileString(messageType+".json", schema)
+	if err != nil {
+		return err
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.schemas[messageType] = compiled
+
+	return nil
+}
+
+// Validate checks the body against the schema registered for messageType and returns a *ValidationError if it does not match
+func (r *Registry) Validate(messageType string, body string) error {
+	r.mx.RLock()
+	schema, ok := r.schemas[messageType]
+	r.mx.RUnlock()
+
+	if !ok {
+		if r.Strict {
+			return &ValidationError{MessageType: messageType, Violations: []string{"no schema registered"}}
+		}
+		return nil
+	}
+
+	var instance interface{}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&instance); err != nil {
+		return &ValidationError{MessageType: messageType, Violations: []string{"invalid json: " + err.Error()}}
+	}
+
+	if err := schema.Validate(instance); err != nil {
+		validationErr, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			return err
+		}
+
+		return &ValidationError{MessageType: messageType, Violations: violations(validationErr)}
+	}
+
+	return nil
+}
+
+// ValidateContent validates bodies of JSON content types like Validate. Bodies of other content types, e.g. encoded
+// by the msgpack or protobuf codec, cannot be checked against a JSON schema and are rejected if a schema applies.
+// An empty content type is taken as JSON.
+func (r *Registry) ValidateContent(messageType string, contentType string, body string) error {
+	if isJSON(contentType) {
+		return r.Validate(messageType, body)
+	}
+
+	r.mx.RLock()
+	_, ok := r.schemas[messageType]
+	r.mx.RUnlock()
+
+	if !ok && !r.Strict {
+		return nil
+	}
+
+	return &ValidationError{
+		MessageType: messageType,
+		Violations:  []string{fmt.Sprintf("content type '%s' cannot be validated against a json schema", contentType)},
+	}
+}
+
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// violations flattens the error tree to the leaf errors which name the actual problems
+func violations(err *jsonschema.ValidationError) []string {
+	if len(err.Causes) == 0 {
+		location := err.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		return []string{fmt.Sprintf("%s: %s", location, err.Message)}
+	}
+
+	result := []string{}
+	for _, cause := range err.Causes {
+		result = append(result, violations(cause)...)
+	}
+
+	return result
+}
+
+type ValidationError struct {
+	MessageType string
+	Violations  []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("message of type '%s' violates its schema: %s", e.MessageType, strings.Join(e.Violations, ", "))
+}
@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const productSchema = `{
+	"type": "object",
+	"required": ["sku", "price"],
+	"properties": {
+		"sku": {"type": "string"},
+		"price": {"type": "number", "minimum": 0}
+	}
+}`
+
+func TestRegistry_Validate(t *testing.T) {
+	registry := NewRegistry()
+	require.Nil(t, registry.Register("product", productSchema))
+
+	assert.Nil(t, registry.Validate("product", `{"sku":"foo","price":12.5}`))
+	assert.Nil(t, registry.Validate("unknown", `foo`))
+
+	err := registry.Validate("product", `{"sku":1,"price":-1}`)
+	require.IsType(t, &ValidationError{}, err)
+	assert.Equal(t, "product", err.(*ValidationError).MessageType)
+	assert.Len(t, err.(*ValidationError).Violations, 2)
+	assert.Contains(t, err.Error(), "/sku")
+	assert.Contains(t, err.Error(), "/price")
+
+	err = registry.Validate("product", `{"sku":`)
+	assert.IsType(t, &ValidationError{}, err)
+}
+
+func TestRegistry_Strict(t *testing.T) {
+	registry := NewRegistry()
+	registry.Strict = true
+
+	assert.IsType(t, &ValidationError{}, registry.Validate("unknown", `{}`))
+}
+
+func TestRegistry_ValidateContent(t *testing.T) {
+	registry := NewRegistry()
+	require.Nil(t, registry.Register("product", productSchema))
+
+	assert.Nil(t, registry.ValidateContent("product", "", `{"sku":"foo","price":12.5}`))
+	assert.Nil(t, registry.ValidateContent("product", "application/json; charset=utf-8", `{"sku":"foo","price":12.5}`))
+	assert.Nil(t, registry.ValidateContent("product", "application/vnd.product+json", `{"sku":"foo","price":12.5}`))
+	assert.IsType(t, &ValidationError{}, registry.ValidateContent("product", "application/json", `{"sku":1}`))
+
+	err := registry.ValidateContent("product", "application/msgpack", "gqNza3WjZm9v")
+	require.IsType(t, &ValidationError{}, err)
+	assert.Contains(t, err.Error(), "application/msgpack")
+	assert.Nil(t, registry.ValidateContent("unknown", "application/msgpack", "gqNza3WjZm9v"))
+
+	registry.Strict = true
+	assert.IsType(t, &ValidationError{}, registry.ValidateContent("unknown", "application/msgpack", "gqNza3WjZm9v"))
+}
+
+func TestRegistry_RegisterErr(t *testing.T) {
+	assert.Error(t, NewRegistry().Register("product", `{"type": 1}`))
+}
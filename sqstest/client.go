@@ -0,0 +1,251 @@
+// Package sqstest provides an in-memory SQS for tests. Client implements the parts of the SQS API used by
+// queue.Consumer, publish.Publisher and the queue provisioning with the semantics of SQS: visibility timeouts,
+// receipt handles, receive counts, FIFO message groups and deduplication, delays, batch limits and redrive
+// to dead-letter queues. Server serves a Client over the SQS JSON protocol for SDK clients.
+package sqstest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	DefaultRegion    = "eu-central-1"
+	DefaultAccountID = "000000000000"
+)
+
+// pollInterval is the interval long polling receives check for messages becoming visible
+const pollInterval = 10 * time.Millisecond
+
+// Client is an in-memory SQS, safe for concurrent use. Queues have to be created before use.
+type Client struct {
+	Region    string
+	AccountID string
+	// Endpoint is the base of the queue URLs, https://sqs.<region>.amazonaws.com if empty
+	Endpoint string
+
+	queues map[string]*sqsQueue
+	// offset shifts the clock of the client, see Advance
+	offset time.Duration
+	// changed is closed and replaced whenever messages got sent or became visible
+	changed chan struct{}
+	mx      sync.Mutex
+}
+
+func NewClient() *Client {
+	return &Client{
+		Region:    DefaultRegion,
+		AccountID: DefaultAccountID,
+		queues:    map[string]*sqsQueue{},
+		changed:   make(chan struct{}),
+	}
+}
+
+// Advance moves the clock of the client forward, e.g. to expire visibility timeouts and delays without waiting
+func (c *Client) Advance(d time.Duration) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.offset += d
+	c.notify()
+}
+
+// MustCreateQueue creates a queue with the attributes and returns its URL, it panics if the queue is invalid
+func (c *Client) MustCreateQueue(name string, attributes map[string]string) string {
+	output, err := c.CreateQueue(context.Background(), &sqs.CreateQueueInput{QueueName: aws.String(name), Attributes: attributes})
+	if err != nil {
+		panic(err)
+	}
+
+	return aws.ToString(output.QueueUrl)
+}
+
+func (c *Client) CreateQueue(_ context.Context, input *sqs.CreateQueueInput, _ ...func(*sqs.Options)) (*sqs.CreateQueueOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	name := aws.ToString(input.QueueName)
+	if existing := c.queueByName(name); existing != nil {
+		for key, value := range input.Attributes {
+			if existing.attributes[key] != value {
+				return nil, &types.QueueNameExists{Message: aws.String(fmt.Sprintf("queue %s exists with different attributes", name))}
+			}
+		}
+
+		return &sqs.CreateQueueOutput{QueueUrl: aws.String(existing.url)}, nil
+	}
+
+	q, err := newQueue(name, c.queueURL(name), c.queueARN(name), input.Attributes, c.now())
+	if err != nil {
+		return nil, err
+	}
+	c.queues[q.url] = q
+
+	return &sqs.CreateQueueOutput{QueueUrl: aws.String(q.url)}, nil
+}
+
+func (c *Client) GetQueueUrl(_ context.Context, input *sqs.GetQueueUrlInput, _ ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	owner := aws.ToString(input.QueueOwnerAWSAccountId)
+	q := c.queueByName(aws.ToString(input.QueueName))
+	if q == nil || (owner != "" && owner != c.accountID()) {
+		return nil, queueDoesNotExist(aws.ToString(input.QueueName))
+	}
+
+	return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(q.url)}, nil
+}
+
+func (c *Client) GetQueueAttributes(_ context.Context, input *sqs.GetQueueAttributesInput, _ ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	q, err := c.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, name := range input.AttributeNames {
+		names = append(names, string(name))
+	}
+
+	attributes, err := q.queueAttributes(names, c.now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqs.GetQueueAttributesOutput{Attributes: attributes}, nil
+}
+
+func (c *Client) SetQueueAttributes(_ context.Context, input *sqs.SetQueueAttributesInput, _ ...func(*sqs.Options)) (*sqs.SetQueueAttributesOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	q, err := c.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = q.setAttributes(input.Attributes, false, c.now()); err != nil {
+		return nil, err
+	}
+
+	return &sqs.SetQueueAttributesOutput{}, nil
+}
+
+func (c *Client) DeleteQueue(_ context.Context, input *sqs.DeleteQueueInput, _ ...func(*sqs.Options)) (*sqs.DeleteQueueOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	q, err := c.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	delete(c.queues, q.url)
+
+	return &sqs.DeleteQueueOutput{}, nil
+}
+
+func (c *Client) PurgeQueue(_ context.Context, input *sqs.PurgeQueueInput, _ ...func(*sqs.Options)) (*sqs.PurgeQueueOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	q, err := c.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	q.messages = nil
+
+	return &sqs.PurgeQueueOutput{}, nil
+}
+
+func (c *Client) ListQueues(_ context.Context, input *sqs.ListQueuesInput, _ ...func(*sqs.Options)) (*sqs.ListQueuesOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	output := &sqs.ListQueuesOutput{}
+	for url, q := range c.queues {
+		if strings.HasPrefix(q.name, aws.ToString(input.QueueNamePrefix)) {
+			output.QueueUrls = append(output.QueueUrls, url)
+		}
+	}
+
+	return output, nil
+}
+
+func (c *Client) now() time.Time {
+	return time.Now().Add(c.offset)
+}
+
+// notify wakes up waiting long polls, the lock has to be held
+func (c *Client) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *Client) region() string {
+	if c.Region == "" {
+		return DefaultRegion
+	}
+
+	return c.Region
+}
+
+func (c *Client) accountID() string {
+	if c.AccountID == "" {
+		return DefaultAccountID
+	}
+
+	return c.AccountID
+}
+
+func (c *Client) queueURL(name string) string {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://sqs.%s.amazonaws.com", c.region())
+	}
+
+	return fmt.Sprintf("%s/%s/%s", endpoint, c.accountID(), name)
+}
+
+func (c *Client) queueARN(name string) string {
+	return fmt.Sprintf("arn:aws:sqs:%s:%s:%s", c.region(), c.accountID(), name)
+}
+
+func (c *Client) queue(url *string) (*sqsQueue, error) {
+	q, ok := c.queues[aws.ToString(url)]
+	if !ok {
+		return nil, queueDoesNotExist(aws.ToString(url))
+	}
+
+	return q, nil
+}
+
+func (c *Client) queueByName(name string) *sqsQueue {
+	for _, q := range c.queues {
+		if q.name == name {
+			return q
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) queueByARN(arn string) *sqsQueue {
+	for _, q := range c.queues {
+		if q.arn == arn {
+			return q
+		}
+	}
+
+	return nil
+}
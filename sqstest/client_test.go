@@ -0,0 +1,319 @@
+package sqstest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/publish"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/queue"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ queue.SQSClient           = (*Client)(nil)
+	_ queue.SQSScheduleClient   = (*Client)(nil)
+	_ publish.SQSPublisher      = (*Client)(nil)
+	_ utils.SQSQueueURLResolver = (*Client)(nil)
+)
+
+func send(t *testing.T, client *Client, input *sqs.SendMessageInput) string {
+	output, err := client.SendMessage(context.Background(), input)
+	require.Nil(t, err)
+
+	return aws.ToString(output.MessageId)
+}
+
+func receive(t *testing.T, client *Client, url string, max int32) []types.Message {
+	output, err := client.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(url),
+		MaxNumberOfMessages: max,
+		AttributeNames:      []types.QueueAttributeName{"All"},
+	})
+	require.Nil(t, err)
+
+	return output.Messages
+}
+
+func TestClient_VisibilityTimeout(t *testing.T) {
+	client := NewClient()
+	url := client.MustCreateQueue("foo", map[string]string{"VisibilityTimeout": "30"})
+	id := send(t, client, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("bar")})
+
+	first := receive(t, client, url, 10)
+	require.Len(t, first, 1)
+	assert.Equal(t, id, aws.ToString(first[0].MessageId))
+	assert.Equal(t, "1", first[0].Attributes["ApproximateReceiveCount"])
+	assert.Empty(t, receive(t, client, url, 10))
+
+	client.Advance(31 * time.Second)
+	second := receive(t, client, url, 10)
+	require.Len(t, second, 1)
+	assert.Equal(t, "2", second[0].Attributes["ApproximateReceiveCount"])
+	assert.NotEqual(t, aws.ToString(first[0].ReceiptHandle), aws.ToString(second[0].ReceiptHandle))
+
+	_, err := client.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{QueueUrl: aws.String(url), ReceiptHandle: second[0].ReceiptHandle})
+	require.Nil(t, err)
+	client.Advance(time.Minute)
+	assert.Empty(t, receive(t, client, url, 10))
+
+	_, err = client.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{QueueUrl: aws.String(url), ReceiptHandle: aws.String("foo")})
+	var invalid *types.ReceiptHandleIsInvalid
+	assert.ErrorAs(t, err, &invalid)
+}
+
+func TestClient_ChangeMessageVisibility(t *testing.T) {
+	client := NewClient()
+	url := client.MustCreateQueue("foo", nil)
+	send(t, client, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("bar")})
+
+	received := receive(t, client, url, 1)
+	require.Len(t, received, 1)
+
+	_, err := client.ChangeMessageVisibility(context.Background(), &sqs.ChangeMessageVisibilityInput{
+		QueueUrl: aws.String(url), ReceiptHandle: received[0].ReceiptHandle, VisibilityTimeout: 0,
+	})
+	require.Nil(t, err)
+	assert.Len(t, receive(t, client, url, 1), 1)
+
+	_, err = client.ChangeMessageVisibility(context.Background(), &sqs.ChangeMessageVisibilityInput{
+		QueueUrl: aws.String(url), ReceiptHandle: received[0].ReceiptHandle, VisibilityTimeout: 10,
+	})
+	var notInflight *types.MessageNotInflight
+	assert.ErrorAs(t, err, &notInflight)
+}
+
+func TestClient_ChangeMessageVisibilityFromReceive(t *testing.T) {
+	client := NewClient()
+	url := client.MustCreateQueue("foo", nil)
+	send(t, client, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("bar")})
+
+	received := receive(t, client, url, 1)
+	require.Len(t, received, 1)
+	_, err := client.ChangeMessageVisibility(context.Background(), &sqs.ChangeMessageVisibilityInput{
+		QueueUrl: aws.String(url), ReceiptHandle: received[0].ReceiptHandle, VisibilityTimeout: 7200,
+	})
+	require.Nil(t, err)
+	client.Advance(time.Hour)
+
+	_, err = client.ChangeMessageVisibility(context.Background(), &sqs.ChangeMessageVisibilityInput{
+		QueueUrl: aws.String(url), ReceiptHandle: received[0].ReceiptHandle, VisibilityTimeout: 43200,
+	})
+	var apiErr smithy.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "InvalidParameterValue", apiErr.ErrorCode())
+
+	_, err = client.ChangeMessageVisibility(context.Background(), &sqs.ChangeMessageVisibilityInput{
+		QueueUrl: aws.String(url), ReceiptHandle: received[0].ReceiptHandle, VisibilityTimeout: 39000,
+	})
+	assert.Nil(t, err)
+}
+
+func TestClient_FIFOGroupsAndDeduplication(t *testing.T) {
+	client := NewClient()
+	url := client.MustCreateQueue("foo.fifo", map[string]string{"FifoQueue": "true", "ContentBasedDeduplication": "true"})
+
+	sendFIFO := func(body string, group string) string {
+		return send(t, client, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String(body), MessageGroupId: aws.String(group)})
+	}
+	a1 := sendFIFO("a1", "a")
+	sendFIFO("a2", "a")
+	sendFIFO("b1", "b")
+	assert.Equal(t, a1, sendFIFO("a1", "a"))
+
+	first := receive(t, client, url, 1)
+	require.Len(t, first, 1)
+	assert.Equal(t, "a1", aws.ToString(first[0].Body))
+	assert.Equal(t, "a", first[0].Attributes["MessageGroupId"])
+
+	// group a is blocked while a1 is in flight
+	second := receive(t, client, url, 10)
+	require.Len(t, second, 1)
+	assert.Equal(t, "b1", aws.ToString(second[0].Body))
+
+	_, err := client.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{QueueUrl: aws.String(url), ReceiptHandle: first[0].ReceiptHandle})
+	require.Nil(t, err)
+
+	third := receive(t, client, url, 10)
+	require.Len(t, third, 1)
+	assert.Equal(t, "a2", aws.ToString(third[0].Body))
+
+	_, err = client.SendMessage(context.Background(), &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("c")})
+	assert.Error(t, err)
+	_, err = client.SendMessage(context.Background(), &sqs.SendMessageInput{
+		QueueUrl: aws.String(url), MessageBody: aws.String("c"), MessageGroupId: aws.String("c"), DelaySeconds: 10,
+	})
+	assert.Error(t, err)
+}
+
+func TestClient_Delays(t *testing.T) {
+	client := NewClient()
+	url := client.MustCreateQueue("foo", map[string]string{"DelaySeconds": "60"})
+	send(t, client, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("queue delay")})
+	send(t, client, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("message delay"), DelaySeconds: 120})
+
+	attributes, err := client.GetQueueAttributes(context.Background(), &sqs.GetQueueAttributesInput{
+		QueueUrl: aws.String(url), AttributeNames: []types.QueueAttributeName{"ApproximateNumberOfMessagesDelayed"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "2", attributes.Attributes["ApproximateNumberOfMessagesDelayed"])
+	assert.Empty(t, receive(t, client, url, 10))
+
+	client.Advance(61 * time.Second)
+	received := receive(t, client, url, 10)
+	require.Len(t, received, 1)
+	assert.Equal(t, "queue delay", aws.ToString(received[0].Body))
+	_, err = client.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{QueueUrl: aws.String(url), ReceiptHandle: received[0].ReceiptHandle})
+	require.Nil(t, err)
+
+	client.Advance(60 * time.Second)
+	received = receive(t, client, url, 10)
+	require.Len(t, received, 1)
+	assert.Equal(t, "message delay", aws.ToString(received[0].Body))
+}
+
+func TestClient_BatchLimits(t *testing.T) {
+	client := NewClient()
+	url := client.MustCreateQueue("foo", map[string]string{"MaximumMessageSize": "1024"})
+
+	entries := []types.SendMessageBatchRequestEntry{}
+	for i := 0; i < 11; i++ {
+		entries = append(entries, types.SendMessageBatchRequestEntry{Id: aws.String(strconv.Itoa(i)), MessageBody: aws.String("bar")})
+	}
+	_, err := client.SendMessageBatch(context.Background(), &sqs.SendMessageBatchInput{QueueUrl: aws.String(url), Entries: entries})
+	var tooMany *types.TooManyEntriesInBatchRequest
+	assert.ErrorAs(t, err, &tooMany)
+
+	_, err = client.SendMessageBatch(context.Background(), &sqs.SendMessageBatchInput{QueueUrl: aws.String(url), Entries: []types.SendMessageBatchRequestEntry{entries[0], entries[0]}})
+	var notDistinct *types.BatchEntryIdsNotDistinct
+	assert.ErrorAs(t, err, &notDistinct)
+
+	large := types.SendMessageBatchRequestEntry{Id: aws.String("large"), MessageBody: aws.String(string(make([]byte, 2048)))}
+	output, err := client.SendMessageBatch(context.Background(), &sqs.SendMessageBatchInput{QueueUrl: aws.String(url), Entries: []types.SendMessageBatchRequestEntry{entries[0], large}})
+	require.Nil(t, err)
+	assert.Len(t, output.Successful, 1)
+	require.Len(t, output.Failed, 1)
+	assert.Equal(t, "large", aws.ToString(output.Failed[0].Id))
+	assert.Equal(t, "InvalidParameterValue", aws.ToString(output.Failed[0].Code))
+	assert.True(t, output.Failed[0].SenderFault)
+}
+
+func TestClient_RedriveToDeadLetterQueue(t *testing.T) {
+	client := NewClient()
+	dlq := client.MustCreateQueue("foo-dlq", nil)
+	url := client.MustCreateQueue("foo", map[string]string{
+		"RedrivePolicy": fmt.Sprintf(`{"deadLetterTargetArn":"arn:aws:sqs:%s:%s:foo-dlq","maxReceiveCount":"2"}`, DefaultRegion, DefaultAccountID),
+	})
+	id := send(t, client, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("bar")})
+
+	for i := 0; i < 2; i++ {
+		assert.Len(t, receive(t, client, url, 1), 1)
+		client.Advance(31 * time.Second)
+	}
+	assert.Empty(t, receive(t, client, url, 1))
+
+	received := receive(t, client, dlq, 1)
+	require.Len(t, received, 1)
+	assert.Equal(t, id, aws.ToString(received[0].MessageId))
+	assert.Equal(t, "arn:aws:sqs:eu-central-1:000000000000:foo", received[0].Attributes["DeadLetterQueueSourceArn"])
+}
+
+func TestClient_LongPolling(t *testing.T) {
+	client := NewClient()
+	url := client.MustCreateQueue("foo", nil)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		send(t, client, &sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("bar")})
+	}()
+
+	start := time.Now()
+	output, err := client.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{QueueUrl: aws.String(url), WaitTimeSeconds: 5})
+	require.Nil(t, err)
+	assert.Len(t, output.Messages, 1)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestClient_GetQueueUrl(t *testing.T) {
+	client := NewClient()
+	url := client.MustCreateQueue("foo", nil)
+
+	output, err := client.GetQueueUrl(context.Background(), &sqs.GetQueueUrlInput{QueueName: aws.String("foo")})
+	require.Nil(t, err)
+	assert.Equal(t, url, aws.ToString(output.QueueUrl))
+	assert.Equal(t, "https://sqs.eu-central-1.amazonaws.com/000000000000/foo", url)
+
+	_, err = client.GetQueueUrl(context.Background(), &sqs.GetQueueUrlInput{QueueName: aws.String("bar")})
+	var notFound *types.QueueDoesNotExist
+	assert.ErrorAs(t, err, &notFound)
+}
+
+type collectingHandler struct {
+	bodies []string
+	mx     sync.Mutex
+}
+
+func (h *collectingHandler) Handle(_ context.Context, messages []types.Message) error {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	for _, m := range messages {
+		h.bodies = append(h.bodies, aws.ToString(m.Body))
+	}
+
+	return nil
+}
+
+func (h *collectingHandler) count() int {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	return len(h.bodies)
+}
+
+func TestClient_ConsumerEndToEnd(t *testing.T) {
+	client := NewClient()
+	url := client.MustCreateQueue("foo", nil)
+
+	publisher, err := publish.NewPublisher(publish.PublisherConfig{QueueName: "foo"}, client)
+	require.Nil(t, err)
+
+	messages := []interface{}{}
+	for i := 0; i < 15; i++ {
+		messages = append(messages, map[string]int{"foo": i})
+	}
+	results, err := publisher.PublishBatch(context.Background(), messages, publish.WithStringAttribute("Tenant", "de"))
+	require.Nil(t, err)
+	assert.Len(t, results, 15)
+
+	handler := &collectingHandler{}
+	consumer, err := queue.NewConsumer(queue.ConsumerConfig{QueueName: "foo", MaxNumberOfMessages: 10, WaitTimeSeconds: 1, VisibilityTimeout: 30}, client, handler)
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		consumer.Start(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return handler.count() == 15 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	attributes, err := client.GetQueueAttributes(context.Background(), &sqs.GetQueueAttributesInput{
+		QueueUrl: aws.String(url), AttributeNames: []types.QueueAttributeName{"All"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "0", attributes.Attributes["ApproximateNumberOfMessages"])
+	assert.Equal(t, "0", attributes.Attributes["ApproximateNumberOfMessagesNotVisible"])
+}
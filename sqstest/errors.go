@@ -0,0 +1,43 @@
+package sqstest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+)
+
+func invalidParameter(format string, args ...interface{}) error {
+	return &smithy.GenericAPIError{
+		Code:    "InvalidParameterValue",
+		Message: fmt.Sprintf(format, args...),
+		Fault:   smithy.FaultClient,
+	}
+}
+
+func invalidAttributeValue(name string, value string) error {
+	return &types.InvalidAttributeValue{Message: aws.String(fmt.Sprintf("invalid value %s for attribute %s", value, name))}
+}
+
+func queueDoesNotExist(queue string) error {
+	return &types.QueueDoesNotExist{Message: aws.String(fmt.Sprintf("the queue %s does not exist", queue))}
+}
+
+func receiptHandleIsInvalid(receiptHandle string) error {
+	return &types.ReceiptHandleIsInvalid{Message: aws.String(fmt.Sprintf("the receipt handle %s is invalid", receiptHandle))}
+}
+
+// failedEntry reports the error of a batch entry, all errors of entries are caused by the sender
+func failedEntry(id *string, err error) types.BatchResultErrorEntry {
+	entry := types.BatchResultErrorEntry{Id: id, Message: aws.String(err.Error()), SenderFault: true}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		entry.Code = aws.String(apiErr.ErrorCode())
+		entry.Message = aws.String(apiErr.ErrorMessage())
+	}
+
+	return entry
+}
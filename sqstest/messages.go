@@ -0,0 +1,485 @@
+package sqstest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"time"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/gofrs/uuid"
+)
+
+var batchEntryIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,80}$`)
+
+// sendRequest is a single message or batch entry to send
+type sendRequest struct {
+	body             string
+	attributes       map[string]types.MessageAttributeValue
+	systemAttributes map[string]types.MessageSystemAttributeValue
+	groupID          *string
+	deduplicationID  *string
+	delaySeconds     int32
+}
+
+func (c *Client) SendMessage(_ context.Context, input *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	q, err := c.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := c.send(q, sendRequest{
+		body:             aws.ToString(input.MessageBody),
+		attributes:       input.MessageAttributes,
+		systemAttributes: input.MessageSystemAttributes,
+		groupID:          input.MessageGroupId,
+		deduplicationID:  input.MessageDeduplicationId,
+		delaySeconds:     input.DelaySeconds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqs.SendMessageOutput{
+		MessageId:              aws.String(m.id),
+		SequenceNumber:         optional(m.sequenceNumber),
+		MD5OfMessageBody:       aws.String(utils.BodyMD5(m.body)),
+		MD5OfMessageAttributes: attributesMD5(input.MessageAttributes),
+	}, nil
+}
+
+func (c *Client) SendMessageBatch(_ context.Context, input *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	q, err := c.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []*string{}
+	size := 0
+	for _, entry := range input.Entries {
+		ids = append(ids, entry.Id)
+		size += messageSize(aws.ToString(entry.MessageBody), entry.MessageAttributes)
+	}
+	if err = validateBatch(ids); err != nil {
+		return nil, err
+	}
+	if size > maxMessageSize {
+		return nil, &types.BatchRequestTooLong{Message: aws.String(fmt.Sprintf("batch requests may be %d bytes long at most", maxMessageSize))}
+	}
+
+	output := &sqs.SendMessageBatchOutput{}
+	for _, entry := range input.Entries {
+		m, err := c.send(q, sendRequest{
+			body:             aws.ToString(entry.MessageBody),
+			attributes:       entry.MessageAttributes,
+			systemAttributes: entry.MessageSystemAttributes,
+			groupID:          entry.MessageGroupId,
+			deduplicationID:  entry.MessageDeduplicationId,
+			delaySeconds:     entry.DelaySeconds,
+		})
+		if err != nil {
+			output.Failed = append(output.Failed, failedEntry(entry.Id, err))
+			continue
+		}
+
+		output.Successful = append(output.Successful, types.SendMessageBatchResultEntry{
+			Id:                     entry.Id,
+			MessageId:              aws.String(m.id),
+			SequenceNumber:         optional(m.sequenceNumber),
+			MD5OfMessageBody:       aws.String(utils.BodyMD5(m.body)),
+			MD5OfMessageAttributes: attributesMD5(entry.MessageAttributes),
+		})
+	}
+
+	return output, nil
+}
+
+// send validates and enqueues the message, a duplicate within the deduplication window of a FIFO queue is returned instead
+func (c *Client) send(q *sqsQueue, request sendRequest) (*message, error) {
+	now := c.now()
+	q.expire(now)
+
+	if request.body == "" {
+		return nil, invalidParameter("the message body must not be empty")
+	}
+	if size := messageSize(request.body, request.attributes); size > q.maxMessageSize {
+		return nil, invalidParameter("the message is %d bytes long, the queue accepts %d bytes at most", size, q.maxMessageSize)
+	}
+	if request.delaySeconds < 0 || request.delaySeconds > maxDelaySeconds {
+		return nil, invalidParameter("DelaySeconds has to be between 0 and %d", maxDelaySeconds)
+	}
+
+	m := &message{
+		body:             request.body,
+		attributes:       request.attributes,
+		systemAttributes: request.systemAttributes,
+		sent:             now,
+	}
+
+	if !q.fifo {
+		if request.groupID != nil || request.deduplicationID != nil {
+			return nil, invalidParameter("MessageGroupId and MessageDeduplicationId are only supported by FIFO queues")
+		}
+
+		delay := q.delaySeconds
+		if request.delaySeconds > 0 {
+			delay = int(request.delaySeconds)
+		}
+		m.visibleAt = now.Add(time.Duration(delay) * time.Second)
+
+		return c.enqueue(q, m), nil
+	}
+
+	if request.delaySeconds > 0 {
+		return nil, invalidParameter("FIFO queues do not support DelaySeconds per message")
+	}
+	if aws.ToString(request.groupID) == "" {
+		return nil, invalidParameter("the request must contain the parameter MessageGroupId")
+	}
+
+	m.groupID = aws.ToString(request.groupID)
+	m.deduplicationID = aws.ToString(request.deduplicationID)
+	if m.deduplicationID == "" {
+		if !q.contentDedup {
+			return nil, invalidParameter("the queue should either have ContentBasedDeduplication enabled or MessageDeduplicationId provided explicitly")
+		}
+		sum := sha256.Sum256([]byte(m.body))
+		m.deduplicationID = hex.EncodeToString(sum[:])
+	}
+
+	if d, ok := q.deduplication[m.deduplicationID]; ok {
+		return &message{id: d.messageID, body: m.body, sequenceNumber: d.sequenceNumber}, nil
+	}
+
+	q.sequence++
+	m.sequenceNumber = fmt.Sprintf("%020d", q.sequence)
+	m.visibleAt = now.Add(time.Duration(q.delaySeconds) * time.Second)
+	c.enqueue(q, m)
+
+	q.deduplication[m.deduplicationID] = deduplicated{
+		messageID:      m.id,
+		sequenceNumber: m.sequenceNumber,
+		expires:        now.Add(deduplicationWindow),
+	}
+
+	return m, nil
+}
+
+func (c *Client) enqueue(q *sqsQueue, m *message) *message {
+	id, _ := uuid.NewV4()
+	m.id = id.String()
+
+	q.messages = append(q.messages, m)
+	c.notify()
+
+	return m
+}
+
+// ReceiveMessage returns visible messages, it waits for messages up to WaitTimeSeconds or the queue's ReceiveMessageWaitTimeSeconds
+func (c *Client) ReceiveMessage(ctx context.Context, input *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	maxMessages := input.MaxNumberOfMessages
+	if maxMessages == 0 {
+		maxMessages = 1
+	}
+	if maxMessages < 1 || maxMessages > maxBatchEntries {
+		return nil, invalidParameter("MaxNumberOfMessages has to be between 1 and %d", maxBatchEntries)
+	}
+	if input.VisibilityTimeout < 0 || input.VisibilityTimeout > maxVisibilityTimeout {
+		return nil, invalidParameter("VisibilityTimeout has to be between 0 and %d", maxVisibilityTimeout)
+	}
+	if input.WaitTimeSeconds < 0 || input.WaitTimeSeconds > maxWaitTimeSeconds {
+		return nil, invalidParameter("WaitTimeSeconds has to be between 0 and %d", maxWaitTimeSeconds)
+	}
+
+	c.mx.Lock()
+	q, err := c.queue(input.QueueUrl)
+	if err != nil {
+		c.mx.Unlock()
+		return nil, err
+	}
+	wait := time.Duration(q.waitTimeSeconds) * time.Second
+	if input.WaitTimeSeconds > 0 {
+		wait = time.Duration(input.WaitTimeSeconds) * time.Second
+	}
+	c.mx.Unlock()
+
+	deadline := time.Now().Add(wait)
+	for {
+		c.mx.Lock()
+		messages := c.receive(q, input, int(maxMessages))
+		changed := c.changed
+		c.mx.Unlock()
+
+		if len(messages) > 0 || !time.Now().Before(deadline) {
+			return &sqs.ReceiveMessageOutput{Messages: messages}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// receive makes up to max visible messages invisible and returns them, in order of their groups for FIFO queues.
+// Messages received more often than the redrive policy allows are moved to the dead-letter queue instead.
+func (c *Client) receive(q *sqsQueue, input *sqs.ReceiveMessageInput, max int) []types.Message {
+	now := c.now()
+	q.expire(now)
+
+	visibility := q.visibilityTimeout
+	if input.VisibilityTimeout > 0 {
+		visibility = int(input.VisibilityTimeout)
+	}
+
+	attributeNames := []string{}
+	for _, name := range input.AttributeNames {
+		attributeNames = append(attributeNames, string(name))
+	}
+
+	received := []types.Message{}
+	redriven := []*message{}
+	blocked := map[string]bool{}
+	for _, m := range q.messages {
+		if len(received) >= max {
+			break
+		}
+		if q.fifo && blocked[m.groupID] {
+			continue
+		}
+		if m.visibleAt.After(now) {
+			// later messages of a group wait for the earlier ones to be deleted
+			blocked[m.groupID] = true
+			continue
+		}
+
+		if q.redrive != nil && m.receiveCount >= q.redrive.maxReceiveCount {
+			if dlq := c.queueByARN(q.redrive.deadLetterTargetARN); dlq != nil {
+				redriven = append(redriven, m)
+				c.deadLetter(q, dlq, m, now)
+				continue
+			}
+		}
+
+		handle, _ := uuid.NewV4()
+		m.receiptHandle = handle.String()
+		m.receiveCount++
+		if m.firstReceive.IsZero() {
+			m.firstReceive = now
+		}
+		m.received = now
+		m.visibleAt = now.Add(time.Duration(visibility) * time.Second)
+		q.handles[m.receiptHandle] = m
+
+		attributes := selectMessageAttributes(m.attributes, input.MessageAttributeNames)
+		received = append(received, types.Message{
+			MessageId:              aws.String(m.id),
+			ReceiptHandle:          aws.String(m.receiptHandle),
+			Body:                   aws.String(m.body),
+			MD5OfBody:              aws.String(utils.BodyMD5(m.body)),
+			MessageAttributes:      attributes,
+			MD5OfMessageAttributes: attributesMD5(attributes),
+			Attributes:             selectAttributes(m.systemAttributeValues(c.accountID()), attributeNames),
+		})
+	}
+
+	for _, m := range redriven {
+		q.remove(m)
+	}
+
+	return received
+}
+
+// deadLetter moves the message to the dead-letter queue keeping its id, attributes and receive count
+func (c *Client) deadLetter(source *sqsQueue, dlq *sqsQueue, m *message, now time.Time) {
+	m.receiptHandle = ""
+	m.visibleAt = now
+	m.sourceARN = source.arn
+	dlq.messages = append(dlq.messages, m)
+}
+
+func (c *Client) DeleteMessage(_ context.Context, input *sqs.DeleteMessageInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	q, err := c.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.delete(q, aws.ToString(input.ReceiptHandle)); err != nil {
+		return nil, err
+	}
+
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (c *Client) DeleteMessageBatch(_ context.Context, input *sqs.DeleteMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	q, err := c.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []*string{}
+	for _, entry := range input.Entries {
+		ids = append(ids, entry.Id)
+	}
+	if err = validateBatch(ids); err != nil {
+		return nil, err
+	}
+
+	output := &sqs.DeleteMessageBatchOutput{}
+	for _, entry := range input.Entries {
+		if err = c.delete(q, aws.ToString(entry.ReceiptHandle)); err != nil {
+			output.Failed = append(output.Failed, failedEntry(entry.Id, err))
+			continue
+		}
+		output.Successful = append(output.Successful, types.DeleteMessageBatchResultEntry{Id: entry.Id})
+	}
+
+	return output, nil
+}
+
+// delete deletes the message of the receipt handle. Deleting a deleted message succeeds, FIFO queues
+// only accept the handle of the latest receive.
+func (c *Client) delete(q *sqsQueue, receiptHandle string) error {
+	m, ok := q.handles[receiptHandle]
+	if !ok {
+		return receiptHandleIsInvalid(receiptHandle)
+	}
+	if q.fifo && m.receiptHandle != receiptHandle && q.contains(m) {
+		return receiptHandleIsInvalid(receiptHandle)
+	}
+
+	q.remove(m)
+
+	return nil
+}
+
+func (c *Client) ChangeMessageVisibility(_ context.Context, input *sqs.ChangeMessageVisibilityInput, _ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	q, err := c.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.changeVisibility(q, aws.ToString(input.ReceiptHandle), input.VisibilityTimeout); err != nil {
+		return nil, err
+	}
+
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+func (c *Client) ChangeMessageVisibilityBatch(_ context.Context, input *sqs.ChangeMessageVisibilityBatchInput, _ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityBatchOutput, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	q, err := c.queue(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []*string{}
+	for _, entry := range input.Entries {
+		ids = append(ids, entry.Id)
+	}
+	if err = validateBatch(ids); err != nil {
+		return nil, err
+	}
+
+	output := &sqs.ChangeMessageVisibilityBatchOutput{}
+	for _, entry := range input.Entries {
+		if err = c.changeVisibility(q, aws.ToString(entry.ReceiptHandle), entry.VisibilityTimeout); err != nil {
+			output.Failed = append(output.Failed, failedEntry(entry.Id, err))
+			continue
+		}
+		output.Successful = append(output.Successful, types.ChangeMessageVisibilityBatchResultEntry{Id: entry.Id})
+	}
+
+	return output, nil
+}
+
+// changeVisibility sets the visibility timeout of a message in flight, 0 makes it visible immediately.
+// Like SQS it rejects timeouts ending more than 12 hours after the receive.
+func (c *Client) changeVisibility(q *sqsQueue, receiptHandle string, timeout int32) error {
+	if timeout < 0 || timeout > maxVisibilityTimeout {
+		return invalidParameter("VisibilityTimeout has to be between 0 and %d", maxVisibilityTimeout)
+	}
+
+	m, ok := q.handles[receiptHandle]
+	if !ok {
+		return receiptHandleIsInvalid(receiptHandle)
+	}
+
+	now := c.now()
+	if m.receiptHandle != receiptHandle || !m.inFlight(now) || !q.contains(m) {
+		return &types.MessageNotInflight{Message: aws.String("the message is not in flight")}
+	}
+
+	visibleAt := now.Add(time.Duration(timeout) * time.Second)
+	if visibleAt.After(m.received.Add(maxVisibilityTimeout * time.Second)) {
+		return invalidParameter("Value %d for parameter VisibilityTimeout is invalid. Reason: Total VisibilityTimeout for the message is beyond the limit [%d seconds]", timeout, maxVisibilityTimeout)
+	}
+
+	m.visibleAt = visibleAt
+	c.notify()
+
+	return nil
+}
+
+// validateBatch checks the entry count and ids of a batch request
+func validateBatch(ids []*string) error {
+	if len(ids) == 0 {
+		return &types.EmptyBatchRequest{Message: aws.String("there should be at least one entry in the request")}
+	}
+	if len(ids) > maxBatchEntries {
+		return &types.TooManyEntriesInBatchRequest{Message: aws.String(fmt.Sprintf("the batch request may contain %d entries at most", maxBatchEntries))}
+	}
+
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if !batchEntryIDPattern.MatchString(aws.ToString(id)) {
+			return &types.InvalidBatchEntryId{Message: aws.String(fmt.Sprintf("the batch entry id %s is invalid", aws.ToString(id)))}
+		}
+		if seen[aws.ToString(id)] {
+			return &types.BatchEntryIdsNotDistinct{Message: aws.String(fmt.Sprintf("the batch entry id %s is used twice", aws.ToString(id)))}
+		}
+		seen[aws.ToString(id)] = true
+	}
+
+	return nil
+}
+
+func attributesMD5(attributes map[string]types.MessageAttributeValue) *string {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	return aws.String(utils.AttributesMD5(attributes))
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return aws.String(value)
+}
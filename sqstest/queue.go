@@ -0,0 +1,385 @@
+package sqstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// limits and defaults of SQS
+const (
+	maxBatchEntries      = 10
+	maxMessageSize       = 256 * 1024
+	maxVisibilityTimeout = 12 * 60 * 60
+	maxDelaySeconds      = 15 * 60
+	maxWaitTimeSeconds   = 20
+	deduplicationWindow  = 5 * time.Minute
+)
+
+var defaultAttributes = map[string]string{
+	"VisibilityTimeout":             "30",
+	"DelaySeconds":                  "0",
+	"MessageRetentionPeriod":        "345600",
+	"MaximumMessageSize":            "262144",
+	"ReceiveMessageWaitTimeSeconds": "0",
+}
+
+// attributeRanges are the valid ranges of the numeric queue attributes
+var attributeRanges = map[string][2]int{
+	"VisibilityTimeout":             {0, maxVisibilityTimeout},
+	"DelaySeconds":                  {0, maxDelaySeconds},
+	"MessageRetentionPeriod":        {60, 1209600},
+	"MaximumMessageSize":            {1024, maxMessageSize},
+	"ReceiveMessageWaitTimeSeconds": {0, maxWaitTimeSeconds},
+}
+
+// otherAttributes are accepted and returned as set without effect on the fake
+var otherAttributes = map[string]bool{
+	"Policy":                       true,
+	"KmsMasterKeyId":               true,
+	"KmsDataKeyReusePeriodSeconds": true,
+	"SqsManagedSseEnabled":         true,
+	"RedriveAllowPolicy":           true,
+	"DeduplicationScope":           true,
+	"FifoThroughputLimit":          true,
+}
+
+var queueNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,80}$`)
+
+type redrivePolicy struct {
+	deadLetterTargetARN string
+	maxReceiveCount     int
+}
+
+type deduplicated struct {
+	messageID      string
+	sequenceNumber string
+	expires        time.Time
+}
+
+type message struct {
+	id               string
+	body             string
+	attributes       map[string]types.MessageAttributeValue
+	systemAttributes map[string]types.MessageSystemAttributeValue
+	groupID          string
+	deduplicationID  string
+	sequenceNumber   string
+	sent             time.Time
+	// visibleAt is the end of the delay or visibility timeout
+	visibleAt    time.Time
+	firstReceive time.Time
+	// received is the time of the latest receive, visibility can be extended to 12 hours from it
+	received      time.Time
+	receiveCount  int
+	receiptHandle string
+	// sourceARN is the queue a dead-lettered message got moved from
+	sourceARN string
+}
+
+// inFlight reports whether the message got received and is still invisible
+func (m *message) inFlight(now time.Time) bool {
+	return m.receiptHandle != "" && m.visibleAt.After(now)
+}
+
+type sqsQueue struct {
+	name       string
+	url        string
+	arn        string
+	attributes map[string]string
+	created    time.Time
+	modified   time.Time
+
+	visibilityTimeout int
+	delaySeconds      int
+	retention         time.Duration
+	maxMessageSize    int
+	waitTimeSeconds   int
+	fifo              bool
+	contentDedup      bool
+	redrive           *redrivePolicy
+
+	messages      []*message
+	handles       map[string]*message
+	deduplication map[string]deduplicated
+	sequence      int64
+}
+
+func newQueue(name string, url string, arn string, attributes map[string]string, now time.Time) (*sqsQueue, error) {
+	fifo := attributes["FifoQueue"] == "true"
+	base := strings.TrimSuffix(name, ".fifo")
+	if !queueNamePattern.MatchString(base) || len(name) > 80 {
+		return nil, invalidParameter("queue name %s is invalid", name)
+	}
+	if fifo != strings.HasSuffix(name, ".fifo") {
+		return nil, invalidParameter("the name of a FIFO queue has to end with .fifo, only FIFO queues may")
+	}
+
+	q := &sqsQueue{
+		name:          name,
+		url:           url,
+		arn:           arn,
+		attributes:    map[string]string{},
+		created:       now,
+		handles:       map[string]*message{},
+		deduplication: map[string]deduplicated{},
+	}
+	for key, value := range defaultAttributes {
+		q.attributes[key] = value
+	}
+
+	if err := q.setAttributes(attributes, true, now); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// setAttributes validates and applies the attributes, FifoQueue can only be set on creation
+func (q *sqsQueue) setAttributes(attributes map[string]string, creating bool, now time.Time) error {
+	for key, value := range attributes {
+		switch {
+		case key == "FifoQueue":
+			if !creating {
+				return &types.InvalidAttributeName{Message: aws.String("FifoQueue cannot be changed")}
+			}
+		case key == "ContentBasedDeduplication":
+			if attributes["FifoQueue"] != "true" && !q.fifo {
+				return &types.InvalidAttributeName{Message: aws.String("ContentBasedDeduplication is only supported by FIFO queues")}
+			}
+			if value != "true" && value != "false" {
+				return invalidAttributeValue(key, value)
+			}
+		case key == "RedrivePolicy":
+			if _, err := parseRedrivePolicy(value); err != nil {
+				return err
+			}
+		case attributeRanges[key] != [2]int{}:
+			n, err := strconv.Atoi(value)
+			if err != nil || n < attributeRanges[key][0] || n > attributeRanges[key][1] {
+				return invalidAttributeValue(key, value)
+			}
+		case otherAttributes[key]:
+		default:
+			return &types.InvalidAttributeName{Message: aws.String(fmt.Sprintf("unknown attribute %s", key))}
+		}
+	}
+
+	for key, value := range attributes {
+		q.attributes[key] = value
+	}
+	if !creating {
+		q.modified = now
+	} else {
+		q.modified = q.created
+	}
+
+	q.visibilityTimeout, _ = strconv.Atoi(q.attributes["VisibilityTimeout"])
+	q.delaySeconds, _ = strconv.Atoi(q.attributes["DelaySeconds"])
+	retention, _ := strconv.Atoi(q.attributes["MessageRetentionPeriod"])
+	q.retention = time.Duration(retention) * time.Second
+	q.maxMessageSize, _ = strconv.Atoi(q.attributes["MaximumMessageSize"])
+	q.waitTimeSeconds, _ = strconv.Atoi(q.attributes["ReceiveMessageWaitTimeSeconds"])
+	q.fifo = q.attributes["FifoQueue"] == "true"
+	q.contentDedup = q.attributes["ContentBasedDeduplication"] == "true"
+	q.redrive, _ = parseRedrivePolicy(q.attributes["RedrivePolicy"])
+
+	return nil
+}
+
+// parseRedrivePolicy parses the JSON policy, maxReceiveCount may be a number or a string. An empty policy removes the redrive.
+func parseRedrivePolicy(value string) (*redrivePolicy, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	policy := struct {
+		DeadLetterTargetArn string          `json:"deadLetterTargetArn"`
+		MaxReceiveCount     json.RawMessage `json:"maxReceiveCount"`
+	}{}
+	if err := json.Unmarshal([]byte(value), &policy); err != nil {
+		return nil, invalidAttributeValue("RedrivePolicy", value)
+	}
+
+	count, err := strconv.Atoi(strings.Trim(string(policy.MaxReceiveCount), `"`))
+	if err != nil || count < 1 || count > 1000 || policy.DeadLetterTargetArn == "" {
+		return nil, invalidAttributeValue("RedrivePolicy", value)
+	}
+
+	return &redrivePolicy{deadLetterTargetARN: policy.DeadLetterTargetArn, maxReceiveCount: count}, nil
+}
+
+// queueAttributes returns the requested attributes including the computed ones
+func (q *sqsQueue) queueAttributes(names []string, now time.Time) (map[string]string, error) {
+	q.expire(now)
+
+	all := map[string]string{}
+	for key, value := range q.attributes {
+		all[key] = value
+	}
+	all["QueueArn"] = q.arn
+	all["CreatedTimestamp"] = strconv.FormatInt(q.created.Unix(), 10)
+	all["LastModifiedTimestamp"] = strconv.FormatInt(q.modified.Unix(), 10)
+
+	visible, inFlight, delayed := 0, 0, 0
+	for _, m := range q.messages {
+		switch {
+		case m.inFlight(now):
+			inFlight++
+		case m.visibleAt.After(now):
+			delayed++
+		default:
+			visible++
+		}
+	}
+	all["ApproximateNumberOfMessages"] = strconv.Itoa(visible)
+	all["ApproximateNumberOfMessagesNotVisible"] = strconv.Itoa(inFlight)
+	all["ApproximateNumberOfMessagesDelayed"] = strconv.Itoa(delayed)
+
+	attributes := map[string]string{}
+	for _, name := range names {
+		if name == "All" {
+			return all, nil
+		}
+
+		value, ok := all[name]
+		if !ok && !otherAttributes[name] && name != "RedrivePolicy" && name != "ContentBasedDeduplication" && name != "FifoQueue" {
+			return nil, &types.InvalidAttributeName{Message: aws.String(fmt.Sprintf("unknown attribute %s", name))}
+		}
+		if ok {
+			attributes[name] = value
+		}
+	}
+
+	return attributes, nil
+}
+
+// expire drops messages older than the retention period
+func (q *sqsQueue) expire(now time.Time) {
+	kept := q.messages[:0]
+	for _, m := range q.messages {
+		if now.Sub(m.sent) < q.retention {
+			kept = append(kept, m)
+		}
+	}
+	q.messages = kept
+
+	for id, d := range q.deduplication {
+		if !d.expires.After(now) {
+			delete(q.deduplication, id)
+		}
+	}
+}
+
+func (q *sqsQueue) remove(m *message) {
+	for i, candidate := range q.messages {
+		if candidate == m {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+			return
+		}
+	}
+}
+
+func (q *sqsQueue) contains(m *message) bool {
+	for _, candidate := range q.messages {
+		if candidate == m {
+			return true
+		}
+	}
+
+	return false
+}
+
+// systemAttributes returns the attributes SQS sets on received messages
+func (m *message) systemAttributeValues(senderID string) map[string]string {
+	values := map[string]string{
+		"SenderId":                senderID,
+		"SentTimestamp":           strconv.FormatInt(m.sent.UnixMilli(), 10),
+		"ApproximateReceiveCount": strconv.Itoa(m.receiveCount),
+	}
+	if !m.firstReceive.IsZero() {
+		values["ApproximateFirstReceiveTimestamp"] = strconv.FormatInt(m.firstReceive.UnixMilli(), 10)
+	}
+	if m.groupID != "" {
+		values["MessageGroupId"] = m.groupID
+		values["MessageDeduplicationId"] = m.deduplicationID
+		values["SequenceNumber"] = m.sequenceNumber
+	}
+	if m.sourceARN != "" {
+		values["DeadLetterQueueSourceArn"] = m.sourceARN
+	}
+	for name, value := range m.systemAttributes {
+		if value.StringValue != nil {
+			values[name] = *value.StringValue
+		}
+	}
+
+	return values
+}
+
+// selectAttributes returns the values requested by names, All requests every one
+func selectAttributes(values map[string]string, names []string) map[string]string {
+	if len(names) == 0 {
+		return nil
+	}
+
+	selected := map[string]string{}
+	for _, name := range names {
+		if name == "All" {
+			return values
+		}
+		if value, ok := values[name]; ok {
+			selected[name] = value
+		}
+	}
+
+	return selected
+}
+
+// selectMessageAttributes returns the attributes requested by names, All and .* request every one, foo.* every one prefixed by foo.
+func selectMessageAttributes(attributes map[string]types.MessageAttributeValue, names []string) map[string]types.MessageAttributeValue {
+	selected := map[string]types.MessageAttributeValue{}
+	for _, name := range names {
+		for key, value := range attributes {
+			switch {
+			case name == "All" || name == ".*":
+				selected[key] = value
+			case strings.HasSuffix(name, ".*") && strings.HasPrefix(key, strings.TrimSuffix(name, "*")):
+				selected[key] = value
+			case name == key:
+				selected[key] = value
+			}
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil
+	}
+
+	return selected
+}
+
+// messageSize sums the body and the names, types and values of the attributes like SQS does
+func messageSize(body string, attributes map[string]types.MessageAttributeValue) int {
+	size := len(body)
+
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		attribute := attributes[name]
+		size += len(name) + len(aws.ToString(attribute.DataType)) + len(aws.ToString(attribute.StringValue)) + len(attribute.BinaryValue)
+	}
+
+	return size
+}
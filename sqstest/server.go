@@ -0,0 +1,128 @@
+package sqstest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	targetPrefix    = "AmazonSQS."
+	jsonContentType = "application/x-amz-json-1.0"
+	// errorTypePrefix is the namespace of the error codes in the __type of error responses
+	errorTypePrefix = "com.amazonaws.sqs#"
+)
+
+// operation decodes the JSON input of an action and calls it on the client
+type operation func(ctx context.Context, client *Client, body []byte) (interface{}, error)
+
+var operations = map[string]operation{
+	"CreateQueue":                  handle((*Client).CreateQueue),
+	"GetQueueUrl":                  handle((*Client).GetQueueUrl),
+	"GetQueueAttributes":           handle((*Client).GetQueueAttributes),
+	"SetQueueAttributes":           handle((*Client).SetQueueAttributes),
+	"ListQueues":                   handle((*Client).ListQueues),
+	"DeleteQueue":                  handle((*Client).DeleteQueue),
+	"PurgeQueue":                   handle((*Client).PurgeQueue),
+	"SendMessage":                  handle((*Client).SendMessage),
+	"SendMessageBatch":             handle((*Client).SendMessageBatch),
+	"ReceiveMessage":               handle((*Client).ReceiveMessage),
+	"DeleteMessage":                handle((*Client).DeleteMessage),
+	"DeleteMessageBatch":           handle((*Client).DeleteMessageBatch),
+	"ChangeMessageVisibility":      handle((*Client).ChangeMessageVisibility),
+	"ChangeMessageVisibilityBatch": handle((*Client).ChangeMessageVisibilityBatch),
+}
+
+func handle[I any, O any](call func(*Client, context.Context, *I, ...func(*sqs.Options)) (*O, error)) operation {
+	return func(ctx context.Context, client *Client, body []byte) (interface{}, error) {
+		input := new(I)
+		if err := json.Unmarshal(body, input); err != nil {
+			return nil, &smithy.GenericAPIError{Code: "SerializationException", Message: err.Error(), Fault: smithy.FaultClient}
+		}
+
+		return call(client, ctx, input)
+	}
+}
+
+// Server serves the SQS JSON protocol used by aws-sdk-go-v2 backed by Client, so SDK clients created with the URL
+// of the server as endpoint work without an SQS or Docker. Requests are not authenticated.
+// A Server with a Client can be used as http.Handler of any HTTP server, set the Endpoint of the client to the URL
+// of that server to get matching queue URLs.
+type Server struct {
+	Client *Client
+
+	server *httptest.Server
+}
+
+// NewServer starts a server with a new client on a local port, Close has to be called to stop it
+func NewServer() *Server {
+	s := &Server{Client: NewClient()}
+	s.server = httptest.NewServer(s)
+	s.Client.Endpoint = s.server.URL
+
+	return s
+}
+
+// URL is the endpoint of the server, e.g. for ClientConfig.Endpoint
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close stops the server, blocking till pending requests are done
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target := r.Header.Get("X-Amz-Target")
+	call, ok := operations[strings.TrimPrefix(target, targetPrefix)]
+	if r.Method != http.MethodPost || !strings.HasPrefix(target, targetPrefix) || !ok {
+		writeError(w, &smithy.GenericAPIError{Code: "UnknownOperationException", Message: "unsupported operation " + target, Fault: smithy.FaultClient})
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(body) == 0 {
+		body = []byte("{}")
+	}
+
+	output, err := call(r.Context(), s.Client, body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", jsonContentType)
+	_ = json.NewEncoder(w).Encode(output)
+}
+
+// writeError responds with the code and message of API errors, any other error is an internal failure
+func writeError(w http.ResponseWriter, err error) {
+	code, message, status := "InternalFailure", err.Error(), http.StatusInternalServerError
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code, message = apiErr.ErrorCode(), apiErr.ErrorMessage()
+		if apiErr.ErrorFault() != smithy.FaultServer {
+			status = http.StatusBadRequest
+		}
+	}
+
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"__type":  errorTypePrefix + code,
+		"message": message,
+	})
+}
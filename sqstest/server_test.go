@@ -0,0 +1,110 @@
+package sqstest
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/publish"
+	"git.limango.tech/shop-catalog/libraries/sqs-queue.git/queue"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServerClient(t *testing.T) (*Server, *sqs.Client) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	client, err := queue.NewSQSClient(queue.ClientConfig{
+		Endpoint:     aws.String(server.URL()),
+		AWSAccessKey: "foo",
+		AWSSecretKey: "bar",
+		AWSRegion:    DefaultRegion,
+	})
+	require.Nil(t, err)
+
+	return server, client
+}
+
+func TestServer_SDKClient(t *testing.T) {
+	server, client := newServerClient(t)
+	ctx := context.Background()
+
+	created, err := client.CreateQueue(ctx, &sqs.CreateQueueInput{QueueName: aws.String("foo"), Attributes: map[string]string{"VisibilityTimeout": "60"}})
+	require.Nil(t, err)
+	url, err := client.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String("foo")})
+	require.Nil(t, err)
+	assert.Equal(t, aws.ToString(created.QueueUrl), aws.ToString(url.QueueUrl))
+	assert.Equal(t, server.URL()+"/"+DefaultAccountID+"/foo", aws.ToString(url.QueueUrl))
+
+	publisher, err := publish.NewPublisher(publish.PublisherConfig{QueueName: "foo"}, client)
+	require.Nil(t, err)
+	messages := []interface{}{}
+	for i := 0; i < 12; i++ {
+		messages = append(messages, map[string]int{"foo": i})
+	}
+	results, err := publisher.PublishBatch(ctx, messages, publish.WithStringAttribute("Tenant", "de"), publish.WithBinaryAttribute("Raw", []byte{1, 2}))
+	require.Nil(t, err)
+	assert.Len(t, results, 12)
+
+	received, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl: url.QueueUrl, MaxNumberOfMessages: 10, MessageAttributeNames: []string{"All"}, AttributeNames: []types.QueueAttributeName{"All"},
+	})
+	require.Nil(t, err)
+	require.Len(t, received.Messages, 10)
+	assert.Equal(t, "de", aws.ToString(received.Messages[0].MessageAttributes["Tenant"].StringValue))
+	assert.Equal(t, []byte{1, 2}, received.Messages[0].MessageAttributes["Raw"].BinaryValue)
+	assert.Equal(t, "1", received.Messages[0].Attributes["ApproximateReceiveCount"])
+
+	deletes := []types.DeleteMessageBatchRequestEntry{}
+	changes := []types.ChangeMessageVisibilityBatchRequestEntry{}
+	for i, m := range received.Messages {
+		if i < 5 {
+			deletes = append(deletes, types.DeleteMessageBatchRequestEntry{Id: aws.String(strconv.Itoa(i)), ReceiptHandle: m.ReceiptHandle})
+		} else {
+			changes = append(changes, types.ChangeMessageVisibilityBatchRequestEntry{Id: aws.String(strconv.Itoa(i)), ReceiptHandle: m.ReceiptHandle, VisibilityTimeout: 120})
+		}
+	}
+	deletes = append(deletes, types.DeleteMessageBatchRequestEntry{Id: aws.String("invalid"), ReceiptHandle: aws.String("foo")})
+
+	deleted, err := client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{QueueUrl: url.QueueUrl, Entries: deletes})
+	require.Nil(t, err)
+	assert.Len(t, deleted.Successful, 5)
+	require.Len(t, deleted.Failed, 1)
+	assert.Equal(t, "ReceiptHandleIsInvalid", aws.ToString(deleted.Failed[0].Code))
+
+	changed, err := client.ChangeMessageVisibilityBatch(ctx, &sqs.ChangeMessageVisibilityBatchInput{QueueUrl: url.QueueUrl, Entries: changes})
+	require.Nil(t, err)
+	assert.Len(t, changed.Successful, 5)
+
+	attributes, err := client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl: url.QueueUrl, AttributeNames: []types.QueueAttributeName{"ApproximateNumberOfMessages", "ApproximateNumberOfMessagesNotVisible", "VisibilityTimeout"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"ApproximateNumberOfMessages":           "2",
+		"ApproximateNumberOfMessagesNotVisible": "5",
+		"VisibilityTimeout":                     "60",
+	}, attributes.Attributes)
+
+	_, err = client.PurgeQueue(ctx, &sqs.PurgeQueueInput{QueueUrl: url.QueueUrl})
+	require.Nil(t, err)
+	empty, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{QueueUrl: url.QueueUrl})
+	require.Nil(t, err)
+	assert.Empty(t, empty.Messages)
+}
+
+func TestServer_Errors(t *testing.T) {
+	_, client := newServerClient(t)
+
+	_, err := client.GetQueueUrl(context.Background(), &sqs.GetQueueUrlInput{QueueName: aws.String("foo")})
+	var notExists *types.QueueDoesNotExist
+	assert.ErrorAs(t, err, &notExists)
+
+	_, err = client.CreateQueue(context.Background(), &sqs.CreateQueueInput{QueueName: aws.String("foo"), Attributes: map[string]string{"DelaySeconds": "901"}})
+	var invalid *types.InvalidAttributeValue
+	assert.ErrorAs(t, err, &invalid)
+}
@@ -0,0 +1,16 @@
+package utils
+
+// message attributes set by the publisher on every message
+const (
+	MessageTypeAttribute = "Message-Type"
+	ContentTypeAttribute = "Content-Type"
+)
+
+// message attributes of request/reply messages
+const (
+	ReplyToAttribute       = "Reply-To"
+	CorrelationIDAttribute = "Correlation-Id"
+)
+
+// DeliverAtAttribute carries the RFC 3339 time before which a scheduled message is not handled
+const DeliverAtAttribute = "Deliver-At"
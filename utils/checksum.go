@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// BodyMD5 returns the digest SQS reports for a message body
+func BodyMD5(body string) string {
+	return md5Hex([]byte(body))
+}
+
+// AttributesMD5 digests the attributes the way SQS does: sorted by name, every name, data type and value
+// prefixed by its length and the value by its transport type
+func AttributesMD5(attributes map[string]types.MessageAttributeValue) string {
+	names := []string{}
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := []byte{}
+	field := func(value []byte) {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(value)))
+		buf = append(buf, value...)
+	}
+
+	for _, name := range names {
+		attribute := attributes[name]
+		dataType := aws.ToString(attribute.DataType)
+
+		field([]byte(name))
+		field([]byte(dataType))
+		if strings.HasPrefix(dataType, "Binary") {
+			buf = append(buf, 2)
+			field(attribute.BinaryValue)
+		} else {
+			buf = append(buf, 1)
+			field([]byte(aws.ToString(attribute.StringValue)))
+		}
+	}
+
+	return md5Hex(buf)
+}
+
+func md5Hex(value []byte) string {
+	sum := md5.Sum(value)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// the digests were reported by SQS for the messages
+func TestBodyMD5_KnownDigest(t *testing.T) {
+	assert.Equal(t, "098f6bcd4621d373cade4e832627b4f6", BodyMD5("test"))
+}
+
+func TestAttributesMD5_KnownDigest(t *testing.T) {
+	attributes := map[string]types.MessageAttributeValue{
+		"timestamp": {DataType: aws.String("Number"), StringValue: aws.String("1493147359900")},
+	}
+
+	assert.Equal(t, "235c5c510d26fb653d073faed50ae77c", AttributesMD5(attributes))
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// ErrInvalidConfig is wrapped by the errors of config validations
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Validator is a config able to check its settings
+type Validator interface {
+	Validate() error
+}
+
+// LoadConfig fills spec, a pointer to a config, from the environment variables named by its envconfig tags and
+// validates it. A non-empty prefix is prepended to the variable names, e.g. ORDERS_AWS_SQS_QUEUE_NAME for ORDERS.
+func LoadConfig(prefix string, spec Validator) error {
+	if err := envconfig.Process(prefix, spec); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, err)
+	}
+
+	return spec.Validate()
+}
+
+// InvalidConfig returns an error wrapping ErrInvalidConfig
+func InvalidConfig(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidConfig, fmt.Sprintf(format, args...))
+}
@@ -2,24 +2,200 @@ package utils
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/labstack/gommon/log"
 )
 
+// resolveAttempts of a queue URL on a use of a QueueURL, waiting resolveBackoff doubled on every attempt in between
+const resolveAttempts = 3
+
+var resolveBackoff = 100 * time.Millisecond
+
+// ErrQueueRegionMismatch is returned for queue ARNs of another region or partition than the one of the client
+var ErrQueueRegionMismatch = errors.New("queue ARN is not in the region of the client")
+
 type SQSQueueURLResolver interface {
 	GetQueueUrl(ctx context.Context, input *sqs.GetQueueUrlInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error)
 }
 
+// regionalResolver is implemented by clients knowing their region, like *sqs.Client
+type regionalResolver interface {
+	Options() sqs.Options
+}
+
+// queueURLKey identifies a resolved URL in the cache, the same name may resolve to other URLs with other clients
+type queueURLKey struct {
+	client SQSQueueURLResolver
+	queue  string
+	owner  string
+}
+
+// queueURLs caches the URLs resolved in the process by queueURLKey
+var queueURLs sync.Map
+
+// GetQueueURL resolves the URL of the queue, see ResolveQueueURL
 func GetQueueURL(client SQSQueueURLResolver, queueName string) (*string, error) {
+	queueURL, err := ResolveQueueURL(context.TODO(), client, queueName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return aws.String(queueURL), nil
+}
+
+// ResolveQueueURL returns the URL of the queue given by its URL, ARN or name. URLs are returned as they are, names
+// and ARNs are looked up with GetQueueUrl in the account of the ARN or else of owner, the account of the client
+// if owner is empty. ARNs of another region than the client's fail with ErrQueueRegionMismatch, as GetQueueUrl
+// looks up queues in the region of the client only. Resolved URLs are cached for the lifetime of the process.
+func ResolveQueueURL(ctx context.Context, client SQSQueueURLResolver, queue string, owner string) (string, error) {
+	if strings.HasPrefix(queue, "https://") || strings.HasPrefix(queue, "http://") {
+		return queue, nil
+	}
+
+	name := queue
+	if arn, ok := parseQueueARN(queue); ok {
+		if err := arn.checkRegion(client); err != nil {
+			return "", err
+		}
+		name, owner = arn.name, arn.account
+	}
+
+	key := queueURLKey{client: client, queue: name, owner: owner}
+	cacheable := reflect.TypeOf(client).Comparable()
+	if cacheable {
+		if queueURL, ok := queueURLs.Load(key); ok {
+			return queueURL.(string), nil
+		}
+	}
+
 	params := &sqs.GetQueueUrlInput{
-		QueueName: aws.String(queueName), // Required
+		QueueName: aws.String(name), // Required
 	}
-	out, err := client.GetQueueUrl(context.TODO(), params)
+	if owner != "" {
+		params.QueueOwnerAWSAccountId = aws.String(owner)
+	}
+	out, err := client.GetQueueUrl(ctx, params)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	log.Debugf("sqs queue url is: %s", *out.QueueUrl)
-	return out.QueueUrl, nil
+	queueURL := aws.ToString(out.QueueUrl)
+	if cacheable {
+		queueURLs.Store(key, queueURL)
+	}
+
+	log.Debugf("sqs queue url is: %s", queueURL)
+	return queueURL, nil
+}
+
+// queueARN is a parsed queue ARN like arn:aws:sqs:eu-central-1:123456789012:orders
+type queueARN struct {
+	partition string
+	region    string
+	account   string
+	name      string
+}
+
+func parseQueueARN(queue string) (queueARN, bool) {
+	parts := strings.Split(queue, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "sqs" || parts[4] == "" || parts[5] == "" {
+		return queueARN{}, false
+	}
+
+	return queueARN{partition: parts[1], region: parts[3], account: parts[4], name: parts[5]}, true
+}
+
+// checkRegion fails if the ARN is not in the region of the client, clients with unknown region are not checked
+func (a queueARN) checkRegion(client SQSQueueURLResolver) error {
+	if a.partition != partition(a.region) {
+		return fmt.Errorf("%w: region %s is not in partition %s", ErrQueueRegionMismatch, a.region, a.partition)
+	}
+
+	regional, ok := client.(regionalResolver)
+	if !ok {
+		return nil
+	}
+	if region := regional.Options().Region; region != "" && region != a.region {
+		return fmt.Errorf("%w: queue in %s, client in %s", ErrQueueRegionMismatch, a.region, region)
+	}
+
+	return nil
+}
+
+// partition returns the partition of the region
+func partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	}
+
+	return "aws"
+}
+
+// QueueURL resolves the URL of a queue on first use, e.g. to start services while SQS is briefly unavailable.
+// Every use retries a failed resolution a few times till it succeeds once, see ResolveQueueURL.
+type QueueURL struct {
+	client SQSQueueURLResolver
+	queue  string
+	owner  string
+
+	url atomic.Value
+	// mx serializes resolutions
+	mx sync.Mutex
+}
+
+func NewQueueURL(client SQSQueueURLResolver, queue string, owner string) *QueueURL {
+	return &QueueURL{client: client, queue: queue, owner: owner}
+}
+
+// Get returns the URL, resolving it if it is not yet
+func (q *QueueURL) Get(ctx context.Context) (string, error) {
+	if queueURL := q.String(); queueURL != "" {
+		return queueURL, nil
+	}
+
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	if queueURL := q.String(); queueURL != "" {
+		return queueURL, nil
+	}
+
+	backoff := resolveBackoff
+	for attempt := 1; ; attempt++ {
+		queueURL, err := ResolveQueueURL(ctx, q.client, q.queue, q.owner)
+		if err == nil {
+			q.url.Store(queueURL)
+			return queueURL, nil
+		}
+		if attempt == resolveAttempts {
+			return "", err
+		}
+
+		log.Warnf("resolving sqs queue url of %s failed, retrying: %s", q.queue, err)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+// String returns the URL if it is resolved already, an empty string otherwise
+func (q *QueueURL) String() string {
+	queueURL, _ := q.url.Load().(string)
+
+	return queueURL
 }
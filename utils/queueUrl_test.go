@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type resolverMock struct {
+	inputs   []*sqs.GetQueueUrlInput
+	failures int
+}
+
+func (r *resolverMock) GetQueueUrl(_ context.Context, input *sqs.GetQueueUrlInput, _ ...func(*sqs.Options)) (*sqs.GetQueueUrlOutput, error) {
+	r.inputs = append(r.inputs, input)
+	if r.failures > 0 {
+		r.failures--
+		return nil, errors.New("unavailable")
+	}
+
+	account := aws.ToString(input.QueueOwnerAWSAccountId)
+	if account == "" {
+		account = "000000000000"
+	}
+
+	return &sqs.GetQueueUrlOutput{QueueUrl: aws.String("https://sqs.eu-central-1.amazonaws.com/" + account + "/" + aws.ToString(input.QueueName))}, nil
+}
+
+func TestResolveQueueURL(t *testing.T) {
+	client := &resolverMock{}
+	ctx := context.Background()
+
+	queueURL, err := ResolveQueueURL(ctx, client, "https://sqs.eu-west-1.amazonaws.com/123456789012/foo", "")
+	require.Nil(t, err)
+	assert.Equal(t, "https://sqs.eu-west-1.amazonaws.com/123456789012/foo", queueURL)
+	assert.Empty(t, client.inputs)
+
+	queueURL, err = ResolveQueueURL(ctx, client, "arn:aws:sqs:eu-central-1:123456789012:foo.fifo", "")
+	require.Nil(t, err)
+	assert.Equal(t, "https://sqs.eu-central-1.amazonaws.com/123456789012/foo.fifo", queueURL)
+	assert.Equal(t, "foo.fifo", aws.ToString(client.inputs[0].QueueName))
+
+	queueURL, err = ResolveQueueURL(ctx, client, "foo", "210987654321")
+	require.Nil(t, err)
+	assert.Equal(t, "https://sqs.eu-central-1.amazonaws.com/210987654321/foo", queueURL)
+
+	queueURL, err = ResolveQueueURL(ctx, client, "foo", "")
+	require.Nil(t, err)
+	assert.Equal(t, "https://sqs.eu-central-1.amazonaws.com/000000000000/foo", queueURL)
+	assert.Len(t, client.inputs, 3)
+
+	// cached per client
+	_, err = ResolveQueueURL(ctx, client, "foo", "")
+	require.Nil(t, err)
+	assert.Len(t, client.inputs, 3)
+	_, err = ResolveQueueURL(ctx, &resolverMock{}, "foo", "")
+	require.Nil(t, err)
+}
+
+type regionalResolverMock struct {
+	*resolverMock
+	region string
+}
+
+func (r regionalResolverMock) Options() sqs.Options {
+	return sqs.Options{Region: r.region}
+}
+
+func TestResolveQueueURL_ARNRegion(t *testing.T) {
+	client := regionalResolverMock{resolverMock: &resolverMock{}, region: "eu-central-1"}
+	ctx := context.Background()
+
+	queueURL, err := ResolveQueueURL(ctx, client, "arn:aws:sqs:eu-central-1:123456789012:bar", "")
+	require.Nil(t, err)
+	assert.Equal(t, "https://sqs.eu-central-1.amazonaws.com/123456789012/bar", queueURL)
+
+	for _, arn := range []string{"arn:aws:sqs:eu-west-1:123456789012:bar", "arn:aws-cn:sqs:eu-central-1:123456789012:bar"} {
+		_, err = ResolveQueueURL(ctx, client, arn, "")
+		assert.ErrorIs(t, err, ErrQueueRegionMismatch, arn)
+	}
+	assert.Len(t, client.inputs, 1)
+}
+
+func TestQueueURL_RetriesTillResolved(t *testing.T) {
+	resolveBackoff = time.Millisecond
+	defer func() { resolveBackoff = 100 * time.Millisecond }()
+
+	client := &resolverMock{failures: resolveAttempts + 1}
+	queueURL := NewQueueURL(client, "foo", "")
+
+	_, err := queueURL.Get(context.Background())
+	assert.Error(t, err)
+	assert.Empty(t, queueURL.String())
+	assert.Len(t, client.inputs, resolveAttempts)
+
+	resolved, err := queueURL.Get(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, "https://sqs.eu-central-1.amazonaws.com/000000000000/foo", resolved)
+	assert.Equal(t, resolved, queueURL.String())
+	assert.Len(t, client.inputs, resolveAttempts+2)
+
+	_, err = queueURL.Get(context.Background())
+	require.Nil(t, err)
+	assert.Len(t, client.inputs, resolveAttempts+2)
+}
+
+func TestQueueURL_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewQueueURL(&resolverMock{failures: 1}, "foo", "").Get(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}